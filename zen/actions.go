@@ -1,10 +1,29 @@
 package main
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/eltorocorp/zencli/zen/command"
+	"github.com/eltorocorp/zencli/zen/completion"
+	"github.com/eltorocorp/zencli/zen/config"
+	"github.com/eltorocorp/zencli/zen/editor"
+	"github.com/eltorocorp/zencli/zen/git"
 	"github.com/eltorocorp/zencli/zen/github"
+	"github.com/eltorocorp/zencli/zen/issuetemplate"
+	"github.com/eltorocorp/zencli/zen/shell"
+	"github.com/eltorocorp/zencli/zen/shellwords"
 	"github.com/eltorocorp/zencli/zen/zenhub"
 )
 
@@ -12,6 +31,11 @@ import (
 type Actions struct {
 	githubAPI *github.API
 	zenHubAPI *zenhub.API
+	// repos holds the actions for other repositories (see ForRepo), keyed by owner/name. It is shared by all of
+	// the actions created from the same actions.
+	repos map[string]*Actions
+	// dryRun is where changes to the local repository are written instead of being made (see setDryRun).
+	dryRun io.Writer
 }
 
 // NewActions returns a reference to a set of actions.
@@ -19,14 +43,96 @@ func NewActions(githubAPI *github.API, zenHubAPI *zenhub.API) *Actions {
 	return &Actions{
 		githubAPI: githubAPI,
 		zenHubAPI: zenHubAPI,
+		repos:     map[string]*Actions{},
+	}
+}
+
+// ForRepo returns the actions for the specified repository, using the same credentials. If the owner is empty,
+// the owner of the current repository is assumed. The actions for each repository are kept, so that its ID and
+// board are only fetched once.
+func (a *Actions) ForRepo(owner, repo string) (command.Actions, error) {
+	if owner == "" {
+		owner = a.githubAPI.Owner()
+	}
+	fullName := owner + "/" + repo
+	if strings.EqualFold(fullName, a.githubAPI.FullName()) {
+		return a, nil
+	}
+	if actions, ok := a.repos[strings.ToLower(fullName)]; ok {
+		return actions, nil
+	}
+
+	githubAPI := a.githubAPI.WithRepo(owner, repo)
+	actions := &Actions{
+		githubAPI: githubAPI,
+		zenHubAPI: a.zenHubAPI.WithGitHubAPI(githubAPI),
+		repos:     a.repos,
+		dryRun:    a.dryRun,
+	}
+	a.repos[strings.ToLower(fullName)] = actions
+	return actions, nil
+}
+
+// reportf prints a message saying that a change has been made. Nothing is printed during a dry run, since the
+// change is only described.
+func (a *Actions) reportf(format string, args ...interface{}) {
+	if a.dryRun == nil {
+		fmt.Printf(format, args...)
+	}
+}
+
+// setDryRun turns the dry run on (or off, if w is nil) for these actions and the actions for other repositories.
+func (a *Actions) setDryRun(w io.Writer) {
+	a.dryRun = w
+	a.githubAPI.SetDryRun(w)
+	a.zenHubAPI.SetDryRun(w)
+	for _, actions := range a.repos {
+		actions.githubAPI.SetDryRun(w)
+		actions.zenHubAPI.SetDryRun(w)
 	}
 }
 
 // Create creates a new issue in the specified pipeline.
-func (a *Actions) Create(title, pipelineName string) error {
+//
+// If a template is supplied, its labels and assignees are added to the options, and its body is used if no body
+// was supplied. If the title is empty the issue is composed in the user's editor (starting from the template's
+// title and body), and any metadata supplied in the editor replaces the corresponding options.
+//
+// The body, labels, assignees and milestone are supplied to github when the issue is created. Any remaining
+// steps (moving the issue, setting its estimate and adding it to an epic) are run after the issue has been
+// created. If any of those steps fail the remaining steps are still attempted, and the failures are reported.
+func (a *Actions) Create(title, pipelineName string, options command.CreateOptions) error {
 	var err error
 	var pipelineID string
 
+	defaultTitle := ""
+	if options.Template != "" {
+		template, err := a.findTemplate(options.Template)
+		if err != nil {
+			return err
+		}
+		defaultTitle = template.Title
+		options.Labels = appendMissing(options.Labels, template.Labels...)
+		options.Assignees = appendMissing(options.Assignees, template.Assignees...)
+		if options.Body == "" && options.BodyFile == "" {
+			options.Body = template.Body
+		}
+	}
+
+	if title == "" {
+		if options.BodyFile != "" {
+			options.Body, err = readBodyFile(options.BodyFile)
+			if err != nil {
+				return err
+			}
+			options.BodyFile = ""
+		}
+		title, pipelineName, err = composeIssue(defaultTitle, pipelineName, &options)
+		if err != nil {
+			return err
+		}
+	}
+
 	fmt.Printf("Creating new issue...\n")
 
 	// Since backlog is the default pipeline, we save a few seconds by not checking if it exists (since a move won't be necessary later)
@@ -37,54 +143,426 @@ func (a *Actions) Create(title, pipelineName string) error {
 		}
 	}
 
-	newIssueNumber, err := a.githubAPI.CreateIssue(title)
+	issueToCreate := &github.NewIssue{
+		Title:  title,
+		Body:   options.Body,
+		Labels: options.Labels,
+	}
+
+	if options.BodyFile != "" {
+		issueToCreate.Body, err = readBodyFile(options.BodyFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	if options.Milestone != "" {
+		issueToCreate.Milestone, err = a.githubAPI.GetMilestoneNumber(options.Milestone)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, login := range options.Assignees {
+		if login == "me" {
+			user, err := a.githubAPI.GetAuthenticatedUser()
+			if err != nil {
+				return err
+			}
+			login = user.Login
+		}
+		issueToCreate.Assignees = append(issueToCreate.Assignees, login)
+	}
+
+	newIssueNumber, err := a.githubAPI.CreateIssue(issueToCreate)
+	if err != nil {
+		return err
+	}
+
+	// During a dry run there is no issue number to show, so the messages refer to the new issue instead.
+	newIssueName := fmt.Sprintf("issue %v", newIssueNumber)
+	if newIssueNumber == github.DryRunNumber {
+		newIssueName = "the new issue"
+	}
+	a.reportf("Issue %v created in the backlog.\n", newIssueNumber)
+
+	failures := []string{}
+	if pipelineName != "backlog" {
+		fmt.Printf("Moving %v to %v...\n", newIssueName, pipelineName)
+		err = a.zenHubAPI.MovePipeline(newIssueNumber, pipelineID)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("moving it to %v: %v", pipelineName, err))
+		}
+	}
+
+	if options.Estimate != nil {
+		fmt.Printf("Setting the estimate for %v to %v...\n", newIssueName, *options.Estimate)
+		err = a.zenHubAPI.SetEstimate(newIssueNumber, *options.Estimate)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("setting its estimate: %v", err))
+		}
+	}
+
+	if options.Epic.Number != 0 {
+		epic := a.issueReference(options.Epic)
+		fmt.Printf("Adding %v to epic %v...\n", newIssueName, epic)
+		err = a.addToEpic(options.Epic, newIssueNumber)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("adding it to epic %v: %v", epic, err))
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("%v was created, but the following steps failed:\n - %v", newIssueName, strings.Join(failures, "\n - "))
+	}
+
+	a.reportf("New issue (%v) has been created in %v.\n", newIssueNumber, pipelineName)
+	return nil
+}
+
+// addToEpic adds the issue to the epic, which can be in another repository.
+func (a *Actions) addToEpic(epic command.IssueRef, issue int) error {
+	epicActions, err := a.actionsForRef(epic)
+	if err != nil {
+		return err
+	}
+	epicRepoID, err := epicActions.githubAPI.GetRepoID()
+	if err != nil {
+		return err
+	}
+	return a.zenHubAPI.AddIssueToEpic(*epicRepoID, epic.Number, issue)
+}
+
+// actionsForRef returns the actions for the repository of an issue reference. Plain numbers refer to the issues in
+// this repository.
+func (a *Actions) actionsForRef(ref command.IssueRef) (*Actions, error) {
+	if ref.Repo == "" {
+		return a, nil
+	}
+	actions, err := a.ForRepo(ref.Owner, ref.Repo)
+	if err != nil {
+		return nil, err
+	}
+	return actions.(*Actions), nil
+}
+
+// issueReference returns the text that refers to an issue in a github comment or description written in this
+// repository: #123 for an issue in this repository (which plain numbers refer to), otherwise owner/repo#123.
+func (a *Actions) issueReference(ref command.IssueRef) string {
+	if ref.Repo == "" {
+		return fmt.Sprintf("#%v", ref.Number)
+	}
+	owner := ref.Owner
+	if owner == "" {
+		owner = a.githubAPI.Owner()
+	}
+	if strings.EqualFold(owner+"/"+ref.Repo, a.githubAPI.FullName()) {
+		return fmt.Sprintf("#%v", ref.Number)
+	}
+	return fmt.Sprintf("%v/%v#%v", owner, ref.Repo, ref.Number)
+}
+
+// Comment adds a comment to the specified issue.
+//
+// The body of the comment is read from bodyFile if it is supplied ("-" reads from stdin). If neither a body nor a
+// body file is supplied, the comment is composed in the user's editor.
+func (a *Actions) Comment(issue int, body, bodyFile string) error {
+	var err error
+	if bodyFile != "" {
+		body, err = readBodyFile(bodyFile)
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(body) == "" {
+			return editor.ErrEmptyMessage
+		}
+	} else if body == "" {
+		body, err = editor.ComposeText(fmt.Sprintf(commentTemplate, issue))
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Commenting on issue %v...\n", issue)
+	err = a.githubAPI.CreateComment(issue, body)
+	if err == nil {
+		a.reportf("Your comment has been added to issue %v.\n", issue)
+	}
+	return err
+}
+
+// Comments prints the discussion thread for the specified issue.
+//
+// If since is supplied, only comments created since then are shown (see parseSince). If last is greater than
+// zero, only the last that many comments are shown.
+func (a *Actions) Comments(issue int, since string, last int) error {
+	var sinceTime time.Time
+	var err error
+	if since != "" {
+		sinceTime, err = parseSince(since, time.Now())
+		if err != nil {
+			return err
+		}
+	}
+
+	comments, err := a.githubAPI.GetComments(issue, sinceTime)
+	if err != nil {
+		return err
+	}
+	comments = commentsSince(comments, sinceTime)
+
+	total := len(comments)
+	if last > 0 && last < total {
+		comments = comments[total-last:]
+	}
+
+	fmt.Printf("Comments on issue %v (%v)\n", issue, total)
+	now := time.Now()
+	for _, comment := range comments {
+		fmt.Printf("\n%v commented %v:\n", comment.User.Login, relativeTime(comment.CreatedAt, now))
+		for _, line := range strings.Split(strings.TrimSpace(comment.Body), "\n") {
+			fmt.Printf("    %v\n", strings.TrimRight(line, "\r"))
+		}
+	}
+	return nil
+}
+
+// commentsSince returns the comments that were created at or after the specified time. github filters comments by
+// when they were updated, so older comments that were edited recently are left out here.
+func commentsSince(comments []*github.Comment, since time.Time) []*github.Comment {
+	created := []*github.Comment{}
+	for _, comment := range comments {
+		if !comment.CreatedAt.Before(since) {
+			created = append(created, comment)
+		}
+	}
+	return created
+}
+
+// Show prints the details of the specified issue, and the pull requests that are linked to it.
+func (a *Actions) Show(issue int) error {
+	githubIssue, err := a.githubAPI.GetIssue(issue)
+	if err != nil {
+		return err
+	}
+	issueData, err := a.zenHubAPI.GetIssueData(issue)
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Issue %v created in the backlog.", newIssueNumber)
-	if pipelineName == "backlog" {
+	kind := "Issue"
+	state := githubIssue.State
+	if githubIssue.IsPullRequest() {
+		kind = "Pull request"
+		state, err = pullRequestStatus(a.githubAPI, issue)
+		if err != nil {
+			return err
+		}
+	}
+	fmt.Printf("%v %v: %v\n", kind, issue, githubIssue.Title)
+	fmt.Printf("    State:     %v\n", state)
+	if issueData.Pipeline.Name != "" {
+		fmt.Printf("    Pipeline:  %v\n", issueData.Pipeline.Name)
+	}
+	if issueData.Estimate.Value > 0 {
+		fmt.Printf("    Estimate:  %v\n", issueData.Estimate.Value)
+	}
+	logins := []string{}
+	for _, assignee := range githubIssue.Assignees {
+		logins = append(logins, assignee.Login)
+	}
+	if len(logins) > 0 {
+		fmt.Printf("    Assignees: %v\n", strings.Join(logins, ", "))
+	}
+	labels := []string{}
+	for _, label := range githubIssue.Labels {
+		labels = append(labels, label.Name)
+	}
+	if len(labels) > 0 {
+		fmt.Printf("    Labels:    %v\n", strings.Join(labels, ", "))
+	}
+	if githubIssue.Milestone != nil {
+		fmt.Printf("    Milestone: %v\n", githubIssue.Milestone.Title)
+	}
+	fmt.Printf("    URL:       %v\n", githubIssue.HTMLURL)
+	if body := strings.TrimSpace(githubIssue.Body); body != "" {
 		fmt.Println()
+		for _, line := range strings.Split(body, "\n") {
+			fmt.Printf("    %v\n", strings.TrimRight(line, "\r"))
+		}
+	}
+	if githubIssue.IsPullRequest() {
 		return nil
 	}
 
-	fmt.Printf(" Moving it to %v...\n", pipelineName)
-	err = a.zenHubAPI.MovePipeline(newIssueNumber, pipelineID)
+	linked, err := a.linkedPullRequests(issue)
+	if err != nil {
+		return err
+	}
+	statuses := []*pullRequestRef{}
+	for _, pullRequest := range linked {
+		owner, repo := splitFullName(pullRequest.repo)
+		actions, err := a.ForRepo(owner, repo)
+		if err != nil {
+			return err
+		}
+		statuses = append(statuses, &pullRequestRef{api: actions.(*Actions).githubAPI, number: pullRequest.issue.Number})
+	}
+	err = fetchPullRequestStatuses(statuses)
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("New issue (%v) has been created and moved to %v.\n", newIssueNumber, pipelineName)
+	fmt.Printf("\nLinked pull requests (%v)\n", len(linked))
+	for i, pullRequest := range linked {
+		_, repo := splitFullName(pullRequest.repo)
+		number := strconv.Itoa(pullRequest.issue.Number)
+		if !strings.EqualFold(pullRequest.repo, a.githubAPI.FullName()) {
+			number = repo + "#" + number
+		}
+		fmt.Printf(" - %v%v%v [%v]\n", pr(number, 8), pr(pullRequest.link, 11), pullRequest.issue.Title, statuses[i].status)
+	}
 	return nil
 }
 
+// splitFullName splits a repository's full name into its owner and name.
+func splitFullName(fullName string) (owner, repo string) {
+	if i := strings.Index(fullName, "/"); i >= 0 {
+		return fullName[:i], fullName[i+1:]
+	}
+	return "", fullName
+}
+
 // Drop unassigns the current user from the specified issue.
 func (a *Actions) Drop(issue int) error {
 	fmt.Printf("Removing you from issue %v...\n", issue)
 	err := a.githubAPI.RemoveAuthenticatedUserFromIssue(issue)
 	if err == nil {
-		fmt.Printf("You have been removed from issue %v.\n", issue)
+		a.reportf("You have been removed from issue %v.\n", issue)
+	}
+	return err
+}
+
+// Label adds, removes or sets the labels on the specified issue. The operation must be "add", "remove" or "set".
+func (a *Actions) Label(issue int, operation string, labels []string) error {
+	if len(labels) == 0 && operation != "set" {
+		return fmt.Errorf("at least one label must be supplied to %v", operation)
+	}
+
+	var err error
+	switch operation {
+	case "add":
+		fmt.Printf("Adding %v to issue %v...\n", strings.Join(labels, ", "), issue)
+		err = a.githubAPI.AddLabelsToIssue(issue, labels)
+	case "remove":
+		fmt.Printf("Removing %v from issue %v...\n", strings.Join(labels, ", "), issue)
+		for _, label := range labels {
+			err = a.githubAPI.RemoveLabelFromIssue(issue, label)
+			if err != nil {
+				break
+			}
+		}
+	case "set":
+		fmt.Printf("Setting the labels on issue %v...\n", issue)
+		err = a.githubAPI.SetIssueLabels(issue, labels)
+	default:
+		return fmt.Errorf("'%v' is not a valid label operation. Valid operations are add, remove and set", operation)
+	}
+	if err == nil {
+		a.reportf("The labels on issue %v have been updated.\n", issue)
 	}
 	return err
 }
 
+// Labels lists the labels defined for the current repository.
+func (a *Actions) Labels() error {
+	labels, err := a.githubAPI.GetLabels()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Labels for %v (%v)\n", a.githubAPI.RepoName, len(labels))
+	for _, label := range labels {
+		fmt.Printf(" - %v%v%v\n", swatch(label.Color), pr(label.Name, 25), label.Description)
+	}
+	return nil
+}
+
+// SyncLabels makes the labels for the current repository match the labels defined in the specified file (see
+// loadLabelDefinitions). Labels that are not in the file are deleted. The changes are always printed, and if
+// dryRun is true they are not applied.
+func (a *Actions) SyncLabels(file string, dryRun bool) error {
+	desired, err := loadLabelDefinitions(file)
+	if err != nil {
+		return err
+	}
+
+	existing, err := a.githubAPI.GetLabels()
+	if err != nil {
+		return err
+	}
+
+	changes := planLabelChanges(existing, desired)
+	if len(changes) == 0 {
+		fmt.Printf("The labels for %v already match %v.\n", a.githubAPI.RepoName, file)
+		return nil
+	}
+
+	for _, change := range changes {
+		switch change.action {
+		case "create":
+			fmt.Printf(" + create %v%v%v\n", swatch(change.label.Color), pr(change.label.Name, 25), change.label.Description)
+		case "update":
+			fmt.Printf(" ~ update %v%v%v\n", swatch(change.label.Color), pr(change.name, 25), describeLabelUpdate(existing, change))
+		case "delete":
+			fmt.Printf(" - delete %v\n", change.name)
+		}
+	}
+
+	if dryRun {
+		fmt.Printf("Dry run: %v changes would be made to the labels for %v.\n", len(changes), a.githubAPI.RepoName)
+		return nil
+	}
+
+	for _, change := range changes {
+		switch change.action {
+		case "create":
+			err = a.githubAPI.CreateLabel(change.label)
+		case "update":
+			err = a.githubAPI.UpdateLabel(change.name, change.label)
+		case "delete":
+			err = a.githubAPI.DeleteLabel(change.name)
+		}
+		if err != nil {
+			return fmt.Errorf("unable to %v label '%v': %v", change.action, change.name, err)
+		}
+	}
+	a.reportf("%v changes have been made to the labels for %v.\n", len(changes), a.githubAPI.RepoName)
+	return nil
+}
+
 // List lists all active issues by pipeline.
 //
-// If backlog is true, the backlog pipeline will be included, otherwise the backlog is excluded.
-// If login is non-nil only issues assigned to the specified login are shown (unassigned are still shown).
-func (a *Actions) List(backlog bool, login string) error {
+// If Backlog is true, the backlog pipeline will be included, otherwise the backlog is excluded.
+// If Login is non-nil only issues assigned to the specified login are shown (unassigned are still shown).
+// Pull requests are marked with their status, and can be excluded (NoPRs) or listed on their own (OnlyPRs).
+func (a *Actions) List(options command.ListOptions) error {
 	const unassigned = "unassigned"
 	fmt.Printf("Fetching issues from %v", a.githubAPI.RepoName)
-	githubIssues, err := a.githubAPI.GetIssuesForRepo()
+	pipelines, err := a.zenHubAPI.GetWorkspaceBoard()
 	if err != nil {
 		return err
 	}
-
-	pipelines, err := a.zenHubAPI.GetPipelines()
+	workspace, err := a.zenHubAPI.GetWorkspace()
+	if err != nil {
+		return err
+	}
+	repos, err := a.workspaceRepos(pipelines)
 	if err != nil {
 		return err
 	}
 
+	login := options.Login
 	if login == "me" {
 		user, err := a.githubAPI.GetAuthenticatedUser()
 		if err != nil {
@@ -93,81 +571,1091 @@ func (a *Actions) List(backlog bool, login string) error {
 		login = user.Login
 	}
 
-	fmt.Printf("\rOpen issues for %v\n", pr(a.githubAPI.RepoName+":", 80))
+	title := a.githubAPI.RepoName
+	if workspace != nil {
+		title = workspace.Name
+	}
+	numberWidth := 6
+	if len(repos) > 1 {
+		for _, repo := range repos {
+			if width := len(repo.name) + 8; width > numberWidth {
+				numberWidth = width
+			}
+		}
+	}
+
+	// Each pull request's status takes several requests, so the statuses are fetched concurrently once the rows
+	// to show are known.
+	type listRow struct {
+		number, assignee, name string
+		pullRequest            *pullRequestRef
+	}
+	type listGroup struct {
+		name  string
+		count int
+		rows  []*listRow
+	}
+	groups := []*listGroup{}
+	pullRequests := []*pullRequestRef{}
 	for _, pipeline := range pipelines.List {
-		if backlog == false && pipeline.Name == "Backlog" {
+		if options.Backlog == false && pipeline.Name == "Backlog" {
 			continue
 		}
-		fmt.Printf("%v (%v)\n", pipeline.Name, len(pipeline.Issues))
+		group := &listGroup{name: pipeline.Name, count: len(pipeline.Issues)}
+		groups = append(groups, group)
 		for _, zenhubIssue := range pipeline.Issues {
+			repo := repos[zenhubIssue.RepoID]
+			if repo.api == nil {
+				continue
+			}
 			var issueName string
 			issueAssignee := unassigned
-			for _, githubIssue := range *githubIssues {
-				if githubIssue.Number == zenhubIssue.IssueNumber {
-					issueName = githubIssue.Title
-					if githubIssue.Assignee.Login != "" {
-						issueAssignee = githubIssue.Assignee.Login
-					}
-					break
+			isPullRequest := false
+			if githubIssue, ok := repo.issues[zenhubIssue.IssueNumber]; ok {
+				issueName = githubIssue.Title
+				if githubIssue.Assignee.Login != "" {
+					issueAssignee = githubIssue.Assignee.Login
 				}
+				isPullRequest = githubIssue.IsPullRequest()
 			}
 			if issueAssignee != unassigned && login != "" && issueAssignee != login {
 				continue
 			}
-			fmt.Printf(" - %v%v%v\n", pr(strconv.Itoa(zenhubIssue.IssueNumber), 6), pr(issueAssignee, 15), issueName)
+			if (options.NoPRs && isPullRequest) || (options.OnlyPRs && !isPullRequest) {
+				continue
+			}
+			number := strconv.Itoa(zenhubIssue.IssueNumber)
+			if len(repos) > 1 {
+				number = repo.name + "#" + number
+			}
+			row := &listRow{number: number, assignee: issueAssignee, name: issueName}
+			if isPullRequest {
+				row.pullRequest = &pullRequestRef{api: repo.api, number: zenhubIssue.IssueNumber}
+				pullRequests = append(pullRequests, row.pullRequest)
+			}
+			group.rows = append(group.rows, row)
 		}
 	}
-	return nil
-}
-
-// Move changes the pipeline for the specified issue.
-func (a *Actions) Move(issue int, pipelineName string) error {
-	fmt.Printf("Moving issue %v to %v...\n", issue, pipelineName)
-	pipelineID, err := a.zenHubAPI.GetPipelineID(pipelineName)
+	err = fetchPullRequestStatuses(pullRequests)
 	if err != nil {
 		return err
 	}
 
-	err = a.zenHubAPI.MovePipeline(issue, pipelineID)
-	if err == nil {
-		fmt.Printf("Issue %v has been moved to %v.\n", issue, pipelineName)
+	fmt.Printf("\rOpen issues for %v\n", pr(title+":", 80))
+	for _, group := range groups {
+		fmt.Printf("%v (%v)\n", group.name, group.count)
+		for _, row := range group.rows {
+			name := row.name
+			if row.pullRequest != nil {
+				name = fmt.Sprintf("%v [PR: %v]", name, row.pullRequest.status)
+			}
+			fmt.Printf(" - %v%v%v\n", pr(row.number, numberWidth), pr(row.assignee, 15), name)
+		}
 	}
-	return err
-}
 
-// PickUp assigns the current user as an assignee to the specified issue.
-func (a *Actions) PickUp(issue int) error {
-	fmt.Printf("Assigning you to issue %v...\n", issue)
-	err := a.githubAPI.AssignAuthenticatedUserToIssue(issue)
-	if err == nil {
-		fmt.Printf("You have been assigned to issue %v.\n", issue)
+	ids := []int{}
+	for id, repo := range repos {
+		if repo.err != nil {
+			ids = append(ids, id)
+		}
 	}
-	return err
+	sort.Ints(ids)
+	for _, id := range ids {
+		repo := repos[id]
+		if repo.api == nil {
+			fmt.Printf("Issues from %v are not listed: %v\n", repo.name, repo.err)
+			continue
+		}
+		fmt.Printf("Some issues from %v could not be read: %v\n", repo.name, repo.err)
+	}
+	return nil
 }
 
-// Close chages the status of the specified issue to closed.
-func (a *Actions) Close(issue int) error {
-	fmt.Printf("Closing issue %v...\n", issue)
-	err := a.githubAPI.CloseIssue(issue)
-	if err == nil {
-		fmt.Printf("Issue %v has been closed.\n", issue)
-	}
-	return err
+// boardRepo is a repository on a board, with the issues (and pull requests) that are on the board keyed by number.
+type boardRepo struct {
+	name   string
+	api    *github.API
+	issues map[int]*github.Issue
+	// err is the first error from reading the repository or its issues. If api is nil the repository could not be
+	// read at all.
+	err error
 }
 
-// Open chages the status of the specified issue to open.
-func (a *Actions) Open(issue int) error {
-	fmt.Printf("Openning issue %v...\n", issue)
-	err := a.githubAPI.OpenIssue(issue)
-	if err == nil {
-		fmt.Printf("Issue %v has been opened.\n", issue)
+// boardWorkers is the most board issues that are fetched at once.
+const boardWorkers = 8
+
+// workspaceRepos fetches the issues on the board from each repository that has issues on it, keyed by repository
+// ID. Only the issues on the board are fetched (several at once), rather than every open issue. A repository that
+// cannot be read is returned with its error instead of failing the whole board, except for the target repository.
+func (a *Actions) workspaceRepos(pipelines *zenhub.Pipelines) (map[int]*boardRepo, error) {
+	repoID, err := a.githubAPI.GetRepoID()
+	if err != nil {
+		return nil, err
+	}
+	repos := map[int]*boardRepo{}
+	for id, err := range pipelines.Unreadable {
+		repos[id] = &boardRepo{name: fmt.Sprintf("repository %v", id), err: err}
 	}
-	return err
-}
 
-// Help displays the usage information.
-func (a *Actions) Help() {
-	fmt.Println(usage)
+	type boardIssue struct {
+		repo   *boardRepo
+		number int
+	}
+	boardIssues := []boardIssue{}
+	for _, pipeline := range pipelines.List {
+		for _, issue := range pipeline.Issues {
+			repo, ok := repos[issue.RepoID]
+			if !ok {
+				repo = &boardRepo{name: a.githubAPI.RepoName, api: a.githubAPI, issues: map[int]*github.Issue{}}
+				if issue.RepoID != *repoID {
+					repo.api, repo.err = a.repoAPI(issue.RepoID)
+					if repo.err != nil {
+						repo.name = fmt.Sprintf("repository %v", issue.RepoID)
+					} else {
+						repo.name = repo.api.RepoName
+					}
+				}
+				repos[issue.RepoID] = repo
+			}
+			if repo.api != nil {
+				boardIssues = append(boardIssues, boardIssue{repo: repo, number: issue.IssueNumber})
+			}
+		}
+	}
+
+	var mutex sync.Mutex
+	work := make(chan boardIssue)
+	var wg sync.WaitGroup
+	for i := 0; i < boardWorkers && i < len(boardIssues); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for boardIssue := range work {
+				githubIssue, err := boardIssue.repo.api.GetIssue(boardIssue.number)
+				mutex.Lock()
+				switch {
+				case err == nil:
+					boardIssue.repo.issues[boardIssue.number] = githubIssue
+				case boardIssue.repo.err == nil:
+					boardIssue.repo.err = err
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+	for _, boardIssue := range boardIssues {
+		work <- boardIssue
+	}
+	close(work)
+	wg.Wait()
+	return repos, nil
+}
+
+// repoAPI returns the GitHub API for the repository with the specified ID.
+func (a *Actions) repoAPI(repoID int) (*github.API, error) {
+	repository, err := a.githubAPI.GetRepoByID(repoID)
+	if err != nil {
+		return nil, err
+	}
+	actions, err := a.ForRepo(repository.Owner.Login, repository.Name)
+	if err != nil {
+		return nil, err
+	}
+	return actions.(*Actions).githubAPI, nil
+}
+
+// Workspaces lists the ZenHub workspaces that the repository belongs to.
+func (a *Actions) Workspaces() error {
+	workspaces, err := a.zenHubAPI.GetWorkspaces()
+	if err != nil {
+		return err
+	}
+	if len(workspaces) == 0 {
+		fmt.Printf("%v does not belong to any workspaces.\n", a.githubAPI.RepoName)
+		return nil
+	}
+	selected, err := a.zenHubAPI.GetWorkspace()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Workspaces for %v (%v)\n", a.githubAPI.RepoName, len(workspaces))
+	for _, workspace := range workspaces {
+		marker := " "
+		if selected != nil && workspace.ID == selected.ID {
+			marker = "*"
+		}
+		names := []string{}
+		for _, id := range workspace.Repositories {
+			repository, err := a.githubAPI.GetRepoByID(id)
+			if err != nil {
+				return err
+			}
+			names = append(names, repository.FullName)
+		}
+		fmt.Printf("%v %v%v%v\n", marker, pr(workspace.Name, 30), pr(workspace.ID, 26), strings.Join(names, ", "))
+		if workspace.Description != "" {
+			fmt.Printf("    %v\n", workspace.Description)
+		}
+	}
+	return nil
+}
+
+// Milestones lists the milestones for the current repository along with their issue counts and due dates.
+func (a *Actions) Milestones() error {
+	milestones, err := a.githubAPI.GetMilestones("all")
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Milestones for %v (%v)\n", a.githubAPI.RepoName, len(milestones))
+	for _, milestone := range milestones {
+		due := "no due date"
+		if milestone.DueOn != nil {
+			due = "due " + milestone.DueOn.Format(dateFormat)
+		}
+		counts := fmt.Sprintf("%v open, %v closed", milestone.OpenIssues, milestone.ClosedIssues)
+		fmt.Printf(" - %v%v%v%v\n", pr(milestone.Title, 30), pr(milestone.State, 8), pr(counts, 22), due)
+	}
+	return nil
+}
+
+// SetMilestone adds the specified issue to the specified milestone.
+func (a *Actions) SetMilestone(issue int, milestoneTitle string) error {
+	fmt.Printf("Adding issue %v to %v...\n", issue, milestoneTitle)
+	milestone, err := a.githubAPI.GetMilestoneNumber(milestoneTitle)
+	if err != nil {
+		return err
+	}
+
+	err = a.githubAPI.UpdateIssue(issue, &github.IssuePatch{Milestone: &milestone})
+	if err == nil {
+		a.reportf("Issue %v has been added to %v.\n", issue, milestoneTitle)
+	}
+	return err
+}
+
+// CreateMilestone creates a new milestone. If due is supplied it must be a date (2006-01-02).
+func (a *Actions) CreateMilestone(title, due string) error {
+	var dueOn *time.Time
+	if due != "" {
+		date, err := time.Parse(dateFormat, due)
+		if err != nil {
+			return fmt.Errorf("'%v' is not a valid date (i.e. 2006-01-02)", due)
+		}
+		// Github stores due dates as timestamps and displays them in the viewer's time zone, so noon UTC is used
+		// to keep the date the same in as many time zones as possible.
+		date = date.Add(12 * time.Hour)
+		dueOn = &date
+	}
+
+	fmt.Printf("Creating milestone %v...\n", title)
+	milestone, err := a.githubAPI.CreateMilestone(title, dueOn)
+	if err == nil {
+		a.reportf("Milestone %v (%v) has been created.\n", milestone.Title, milestone.Number)
+	}
+	return err
+}
+
+// Sprint shows the issues (leaving out pull requests) in the specified milestone grouped by pipeline, along with the
+// total points in each pipeline. If no milestone is supplied, the open milestone that is due next is used.
+func (a *Actions) Sprint(milestoneTitle string) error {
+	milestone, err := a.findSprintMilestone(milestoneTitle)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Fetching issues in %v", milestone.Title)
+	issues, err := a.githubAPI.GetIssuesForMilestone(milestone.Number)
+	if err != nil {
+		return err
+	}
+
+	pipelines, err := a.zenHubAPI.GetPipelines()
+	if err != nil {
+		return err
+	}
+
+	issuesByNumber := map[int]*github.Issue{}
+	estimates := map[int]int{}
+	for _, issue := range issues {
+		if !issue.IsPullRequest() {
+			issuesByNumber[issue.Number] = issue
+		}
+	}
+	for _, pipeline := range pipelines.List {
+		for _, zenhubIssue := range pipeline.Issues {
+			estimates[zenhubIssue.IssueNumber] = zenhubIssue.Estimate.Value
+		}
+	}
+
+	type group struct {
+		name   string
+		issues []*github.Issue
+		points []int
+	}
+	groups := []*group{}
+	totalPoints, closedPoints := 0, 0
+	for _, pipeline := range pipelines.List {
+		current := &group{name: pipeline.Name}
+		for _, zenhubIssue := range pipeline.Issues {
+			issue, ok := issuesByNumber[zenhubIssue.IssueNumber]
+			if !ok || issue.State != github.StateOpen {
+				continue
+			}
+			current.issues = append(current.issues, issue)
+			current.points = append(current.points, zenhubIssue.Estimate.Value)
+			totalPoints += zenhubIssue.Estimate.Value
+			delete(issuesByNumber, issue.Number)
+		}
+		groups = append(groups, current)
+	}
+
+	// The estimates of closed issues are taken from the board if they are on it, and otherwise fetched individually.
+	closed := &group{name: "Closed"}
+	for _, issue := range issues {
+		if _, ok := issuesByNumber[issue.Number]; !ok || issue.State != github.StateClosed {
+			continue
+		}
+		points, err := a.estimate(estimates, issue.Number)
+		if err != nil {
+			return err
+		}
+		closed.issues = append(closed.issues, issue)
+		closed.points = append(closed.points, points)
+		totalPoints += points
+		closedPoints += points
+	}
+	groups = append(groups, closed)
+
+	due := "no due date"
+	if milestone.DueOn != nil {
+		due = "due " + milestone.DueOn.Format(dateFormat)
+	}
+	fmt.Printf("\r%v (%v): %v of %v points closed\n", milestone.Title, due, closedPoints, totalPoints)
+	for _, current := range groups {
+		points := 0
+		for _, value := range current.points {
+			points += value
+		}
+		fmt.Printf("%v (%v issues, %v points)\n", current.name, len(current.issues), points)
+		for i, issue := range current.issues {
+			assignee := "unassigned"
+			if issue.Assignee.Login != "" {
+				assignee = issue.Assignee.Login
+			}
+			fmt.Printf(" - %v%v%v%v\n", pr(strconv.Itoa(issue.Number), 6), pr(assignee, 15), pr(strconv.Itoa(current.points[i]), 4), issue.Title)
+		}
+	}
+	return nil
+}
+
+// findSprintMilestone returns the milestone with the specified title. If the title is empty, the open milestone that
+// is due next is returned (see sprintMilestone).
+func (a *Actions) findSprintMilestone(title string) (*github.Milestone, error) {
+	if title != "" {
+		return a.githubAPI.GetMilestone(title)
+	}
+
+	milestones, err := a.githubAPI.GetMilestones(github.StateOpen)
+	if err != nil {
+		return nil, err
+	}
+	if len(milestones) == 0 {
+		return nil, fmt.Errorf("there are no open milestones for %v", a.githubAPI.RepoName)
+	}
+	return sprintMilestone(milestones, time.Now()), nil
+}
+
+// sprintMilestone returns the milestone whose due date is next (today or later), so a milestone that is overdue but
+// still open is passed over. If none are due in the future, the most recently due milestone is returned, or the
+// first milestone if none of them have a due date.
+func sprintMilestone(milestones []*github.Milestone, now time.Time) *github.Milestone {
+	today := startOfDay(now.Local())
+	var next, overdue *github.Milestone
+	for _, milestone := range milestones {
+		switch {
+		case milestone.DueOn == nil:
+		case milestone.DueOn.Before(today):
+			if overdue == nil || milestone.DueOn.After(*overdue.DueOn) {
+				overdue = milestone
+			}
+		case next == nil || milestone.DueOn.Before(*next.DueOn):
+			next = milestone
+		}
+	}
+	switch {
+	case next != nil:
+		return next
+	case overdue != nil:
+		return overdue
+	}
+	return milestones[0]
+}
+
+// PipelineNames returns the names of the pipelines on the board.
+func (a *Actions) PipelineNames() ([]string, error) {
+	return a.zenHubAPI.GetPipelineNames()
+}
+
+// Move changes the pipeline for the specified issue.
+func (a *Actions) Move(issue int, pipelineName string) error {
+	fmt.Printf("Moving issue %v to %v...\n", issue, pipelineName)
+	pipelineID, err := a.zenHubAPI.GetPipelineID(pipelineName)
+	if err != nil {
+		return err
+	}
+
+	err = a.zenHubAPI.MovePipeline(issue, pipelineID)
+	if err == nil {
+		a.reportf("Issue %v has been moved to %v.\n", issue, pipelineName)
+	}
+	return err
+}
+
+// PickUp assigns the current user as an assignee to the specified issue.
+func (a *Actions) PickUp(issue int) error {
+	fmt.Printf("Assigning you to issue %v...\n", issue)
+	err := a.githubAPI.AssignAuthenticatedUserToIssue(issue)
+	if err == nil {
+		a.reportf("You have been assigned to issue %v.\n", issue)
+	}
+	return err
+}
+
+// Close chages the status of the specified issue to closed.
+//
+// The reason can be "completed" (the default), "not_planned" or "duplicate". When closing an issue as a duplicate,
+// duplicateOf must be the original issue (which can be in another repository), and a comment is added to link the
+// issue to the original.
+func (a *Actions) Close(issue int, reason string, duplicateOf command.IssueRef) error {
+	if reason == "" {
+		reason = github.StateReasonCompleted
+	}
+	reason = strings.Replace(strings.ToLower(reason), "-", "_", -1)
+	switch reason {
+	case github.StateReasonCompleted, github.StateReasonNotPlanned:
+		if duplicateOf.Number != 0 {
+			return fmt.Errorf("only duplicate issues can be closed as a duplicate of another issue")
+		}
+	case github.StateReasonDuplicate:
+		if duplicateOf.Number == 0 {
+			return fmt.Errorf("the original issue must be supplied when closing a duplicate (i.e. 'as duplicate of 123')")
+		}
+		original := a.issueReference(duplicateOf)
+		fmt.Printf("Marking issue %v as a duplicate of %v...\n", issue, original)
+		err := a.githubAPI.CreateComment(issue, "Duplicate of "+original)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("'%v' is not a valid reason. Valid reasons are completed, not_planned and duplicate", reason)
+	}
+
+	fmt.Printf("Closing issue %v...\n", issue)
+	state := github.StateClosed
+	err := a.githubAPI.UpdateIssue(issue, &github.IssuePatch{State: &state, StateReason: &reason})
+	if err == nil {
+		a.reportf("Issue %v has been closed as %v.\n", issue, strings.Replace(reason, "_", " ", -1))
+	}
+	return err
+}
+
+// Open chages the status of the specified issue to open.
+func (a *Actions) Open(issue int) error {
+	fmt.Printf("Openning issue %v...\n", issue)
+	state, reason := github.StateOpen, github.StateReasonReopened
+	err := a.githubAPI.UpdateIssue(issue, &github.IssuePatch{State: &state, StateReason: &reason})
+	if err == nil {
+		a.reportf("Issue %v has been opened.\n", issue)
+	}
+	return err
+}
+
+// EditTitle changes the title of the specified issue.
+func (a *Actions) EditTitle(issue int, title string) error {
+	fmt.Printf("Changing the title of issue %v...\n", issue)
+	err := a.githubAPI.UpdateIssue(issue, &github.IssuePatch{Title: &title})
+	if err == nil {
+		a.reportf("The title of issue %v has been changed.\n", issue)
+	}
+	return err
+}
+
+// EditBody changes the body of the specified issue. If clear is true the body is removed, otherwise if the body is
+// empty, the current body of the issue is edited in the user's editor.
+func (a *Actions) EditBody(issue int, body string, clear bool) error {
+	if clear && body != "" {
+		return fmt.Errorf("a body cannot be supplied when clearing the body")
+	}
+	if body == "" && !clear {
+		current, err := a.githubAPI.GetIssue(issue)
+		if err != nil {
+			return err
+		}
+		body, err = editor.ComposeText(fmt.Sprintf(bodyTemplate, current.Body, issue))
+		if err != nil {
+			return err
+		}
+		if body == strings.TrimSpace(current.Body) {
+			fmt.Printf("The body of issue %v is unchanged.\n", issue)
+			return nil
+		}
+	}
+
+	action, result := "Changing", "changed"
+	if clear {
+		action, result = "Clearing", "cleared"
+	}
+	fmt.Printf("%v the body of issue %v...\n", action, issue)
+	err := a.githubAPI.UpdateIssue(issue, &github.IssuePatch{Body: &body})
+	if err == nil {
+		a.reportf("The body of issue %v has been %v.\n", issue, result)
+	}
+	return err
+}
+
+// aliasSection is the section of the config file that holds the user's aliases.
+const aliasSection = "alias"
+
+var aliasNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+// Aliases returns the user's aliases from the [alias] section of the config file.
+func (a *Actions) Aliases() (map[string]string, error) {
+	file, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return file.Section(aliasSection), nil
+}
+
+// ListAliases prints the user's aliases, noting any that cannot be used because a command has the same name.
+func (a *Actions) ListAliases() error {
+	file, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	names := file.Keys(aliasSection)
+	if len(names) == 0 {
+		fmt.Printf("There are no aliases in %v.\n", file.Path())
+		return nil
+	}
+	aliases := file.Section(aliasSection)
+	for _, name := range names {
+		note := ""
+		if command.IsCommand(name) {
+			note = " (ignored, since it is a zen command)"
+		}
+		fmt.Printf("%v %v%v\n", pr(name, 15), aliases[name], note)
+	}
+	return nil
+}
+
+// SetAlias defines (or redefines) an alias.
+func (a *Actions) SetAlias(name, definition string) error {
+	if !aliasNamePattern.MatchString(name) {
+		return fmt.Errorf("'%v' is not a valid alias name; names must start with a letter and contain only letters, numbers, '-' and '_'", name)
+	}
+	if command.IsCommand(name) {
+		return fmt.Errorf("'%v' is a zen command, so it cannot be used as an alias", name)
+	}
+	if strings.TrimSpace(definition) == "" {
+		return errors.New("the command for an alias cannot be empty")
+	}
+
+	file, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	file.Set(aliasSection, name, definition)
+	err = file.Save()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Set alias %v to %v.\n", name, definition)
+	return nil
+}
+
+// DeleteAlias deletes an alias.
+func (a *Actions) DeleteAlias(name string) error {
+	file, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if !file.Delete(aliasSection, name) {
+		return fmt.Errorf("there is no alias named '%v'", name)
+	}
+	err = file.Save()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Deleted alias %v.\n", name)
+	return nil
+}
+
+// Templates lists the issue templates that are available for the current repository.
+func (a *Actions) Templates() error {
+	templates, source, err := a.loadTemplates()
+	if err != nil {
+		return err
+	}
+	if len(templates) == 0 {
+		fmt.Printf("There are no issue templates for %v.\n", a.githubAPI.RepoName)
+		return nil
+	}
+
+	fmt.Printf("Issue templates for %v (from %v)\n", a.githubAPI.RepoName, source)
+	for _, template := range templates {
+		fmt.Printf(" - %v%v%v\n", pr(template.Key, 20), pr(template.Name, 25), template.About)
+	}
+	return nil
+}
+
+// Help displays the usage information, or the detailed usage information for the specified command.
+func (a *Actions) Help(topic string) error {
+	if topic == "" {
+		fmt.Print(command.Usage())
+		return nil
+	}
+	help, err := command.Help(topic)
+	if err != nil {
+		return err
+	}
+	fmt.Print(help)
+	return nil
+}
+
+// Man prints the man page for zen. If a directory is supplied, a page is written to the directory for zen and for
+// each command instead. The format is either "roff" (the default) or "markdown".
+func (a *Actions) Man(format, dir string) error {
+	page, extension := command.ManPage, ".1"
+	if format == "markdown" {
+		page, extension = command.MarkdownPage, ".md"
+	}
+
+	if dir == "" {
+		overview, err := page("")
+		if err != nil {
+			return err
+		}
+		fmt.Print(overview)
+		return nil
+	}
+
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+	for _, topic := range append([]string{""}, command.Topics()...) {
+		name := "zen"
+		if topic != "" {
+			name += "-" + topic
+		}
+		content, err := page(topic)
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(dir, name+extension)
+		err = ioutil.WriteFile(path, []byte(content), 0644)
+		if err != nil {
+			return err
+		}
+		fmt.Println(path)
+	}
+	return nil
+}
+
+// Completion prints the completion script for the specified shell.
+func (a *Actions) Completion(shell string) error {
+	script, err := completion.Script(shell)
+	if err != nil {
+		return err
+	}
+	fmt.Print(script)
+	return nil
+}
+
+// Complete prints the completions for the last of the supplied words, which is the word being completed. The
+// other words are the words before it on the command line. Errors are never returned, since anything printed would
+// be treated as a completion.
+func (a *Actions) Complete(words []string) error {
+	args := []string{}
+	for _, word := range words {
+		args = append(args, completion.Unquote(word))
+	}
+	current := ""
+	if len(args) > 0 {
+		current, args = args[len(args)-1], args[:len(args)-1]
+	}
+	completion.Write(os.Stdout, a.completions(args, current))
+	return nil
+}
+
+// Shell runs an interactive prompt that reads and runs commands until the input ends or "exit" is entered. The
+// same actions are used for every command, so the repository ID, the authenticated user and the board's pipelines
+// are only fetched once.
+func (a *Actions) Shell() error {
+	historyPath := ""
+	if dir, err := os.UserCacheDir(); err == nil {
+		historyPath = filepath.Join(dir, "zen", "shell_history")
+	}
+	reader := shell.NewReader(historyPath, func(args []string, current string) []string {
+		texts := []string{}
+		for _, value := range a.shellCompletions(args, current) {
+			texts = append(texts, value.Text)
+		}
+		return texts
+	})
+
+	fmt.Println("Type a command (i.e. \"list only me\"), \"use repo <owner/name>\" to switch repositories, or \"exit\".")
+	for {
+		line, err := reader.ReadLine(fmt.Sprintf("zen %v> ", a.githubAPI.FullName()))
+		switch {
+		case err == io.EOF:
+			return nil
+		case err == shell.ErrInterrupted:
+			continue
+		case err != nil:
+			return err
+		}
+
+		args, err := shellwords.Split(line)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		if len(args) == 0 {
+			continue
+		}
+		switch {
+		case args[0] == "exit" || args[0] == "quit":
+			return nil
+		case args[0] == "use":
+			err = a.useRepo(args)
+		case args[0] == "shell":
+			err = errors.New("you are already in the zen shell")
+		default:
+			err = command.New(append([]string{"zen"}, args...), a).Execute()
+		}
+		if err != nil {
+			fmt.Println(err)
+		}
+	}
+}
+
+// Run runs the zen commands in the specified file ("-" reads them from stdin as they arrive), one per line, with the
+// same actions, so the board is only fetched once. Blank lines and lines starting with "#" are ignored. A line such
+// as NAME=value defines a variable, which can be used in later lines as $NAME or ${NAME} (environment variables
+// can be used too).
+//
+// By default, a command that fails is reported and the rest of the commands are still run. After a "set -e" line,
+// the first failure stops the script instead ("set +e" turns this off again). If dryRun is true, the requests that
+// would change data are printed instead of being sent.
+func (a *Actions) Run(path string, dryRun bool) error {
+	name, input := "stdin", io.Reader(os.Stdin)
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		name, input = path, file
+	}
+
+	if dryRun {
+		a.setDryRun(os.Stdout)
+		defer a.setDryRun(nil)
+	}
+
+	script := &script{variables: map[string]string{}, dryRun: dryRun}
+	failures := 0
+	scanner := bufio.NewScanner(input)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		err := script.run(a, line)
+		if err == nil {
+			continue
+		}
+		err = fmt.Errorf("%v:%v: %v", name, lineNumber, err)
+		if script.exitOnError {
+			return err
+		}
+		fmt.Println(err)
+		failures++
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if failures > 0 {
+		return fmt.Errorf("%v command(s) in %v failed", failures, name)
+	}
+	return nil
+}
+
+// useRepo switches the repository that commands in the shell act on, for "use repo <owner/name>".
+func (a *Actions) useRepo(args []string) error {
+	if len(args) != 3 || args[1] != "repo" {
+		return errors.New("usage: use repo <owner/name>")
+	}
+	parts := strings.Split(args[2], "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return fmt.Errorf("'%v' is not a repository; expected <owner/name> (i.e. eltorocorp/zencli)", args[2])
+	}
+
+	githubAPI := a.githubAPI.WithRepo(parts[0], parts[1])
+	_, err := githubAPI.GetRepoID()
+	if err != nil {
+		return fmt.Errorf("unable to use %v: %v", args[2], err)
+	}
+	a.githubAPI = githubAPI
+	a.zenHubAPI = a.zenHubAPI.WithGitHubAPI(githubAPI)
+	return nil
+}
+
+// shellCompletions returns the completions for the shell, which are the completions for the command line plus
+// the commands that only exist in the shell.
+func (a *Actions) shellCompletions(args []string, current string) []completion.Value {
+	if len(args) == 0 {
+		builtins := []completion.Value{{Text: "use"}, {Text: "exit"}}
+		return append(completion.Filter(builtins, current, false), a.completions(args, current)...)
+	}
+	if args[0] == "use" {
+		if len(args) == 1 {
+			return completion.Filter([]completion.Value{{Text: "repo"}}, current, false)
+		}
+		return nil
+	}
+	return a.completions(args, current)
+}
+
+// completions returns the values that the current word can be completed with, given the words before it.
+func (a *Actions) completions(args []string, current string) []completion.Value {
+	dir, err := completion.DefaultDir(a.githubAPI.FullName())
+	if err != nil {
+		return nil
+	}
+	cache := completion.NewCache(dir)
+
+	values := []completion.Value{}
+	if len(args) == 0 {
+		aliases, _ := a.Aliases()
+		for _, name := range sortedKeys(aliases) {
+			values = append(values, completion.Filter([]completion.Value{{Text: name, Description: aliases[name]}}, current, false)...)
+		}
+	}
+	for _, candidate := range command.Complete(args) {
+		if candidate.Keyword != "" {
+			values = append(values, completion.Filter([]completion.Value{{Text: candidate.Keyword}}, current, false)...)
+			continue
+		}
+		argumentValues := a.completionValues(cache, candidate.ArgumentType)
+		values = append(values, completion.Filter(argumentValues, current, candidate.ArgumentType.IsList())...)
+	}
+	return values
+}
+
+// composeIssue opens the user's editor to compose the title and body of a new issue. The template is pre-filled
+// with the supplied pipeline and options, and the options are updated with any metadata supplied in the editor.
+func composeIssue(title, pipelineName string, options *command.CreateOptions) (string, string, error) {
+	estimate := ""
+	if options.Estimate != nil {
+		estimate = strconv.Itoa(*options.Estimate)
+	}
+	template := fmt.Sprintf(issueTemplate, title, options.Body, pipelineName, strings.Join(options.Labels, ","), estimate, strings.Join(options.Assignees, ","))
+
+	message, err := editor.ComposeIssue(template)
+	if err != nil {
+		return "", "", err
+	}
+
+	options.Body = message.Body
+	for key, value := range message.Metadata {
+		switch key {
+		case "pipeline":
+			pipelineName = value
+		case "labels":
+			options.Labels = splitList(value)
+		case "assignees":
+			options.Assignees = splitList(value)
+		case "estimate":
+			estimate, err := strconv.Atoi(value)
+			if err != nil {
+				return "", "", fmt.Errorf("the estimate '%v' is not a number", value)
+			}
+			options.Estimate = &estimate
+		}
+	}
+	return message.Title, pipelineName, nil
+}
+
+// sortedKeys returns the keys of the map in alphabetical order.
+func sortedKeys(values map[string]string) []string {
+	keys := []string{}
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// loadConfig loads the user's config file from its default path.
+func loadConfig() (*config.File, error) {
+	path, err := config.DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	return config.Load(path)
+}
+
+// loadTemplates loads the issue templates from the local checkout if it is a checkout of the target repository (its
+// origin is the target repository) and has any, otherwise the templates are loaded from the target repository on
+// github. The source of the templates is returned along with the templates.
+func (a *Actions) loadTemplates() ([]*issuetemplate.Template, string, error) {
+	if a.checkoutIsTarget() {
+		templates, err := issuetemplate.LoadLocal()
+		if err != nil {
+			return nil, "", err
+		}
+		if len(templates) > 0 {
+			return templates, "the local checkout", nil
+		}
+	}
+
+	contents, err := a.githubAPI.GetDirectoryContents(issuetemplate.Dir)
+	if err != nil {
+		return nil, "", err
+	}
+	templates := []*issuetemplate.Template{}
+	for _, content := range contents {
+		if content.Type != "file" || !issuetemplate.IsTemplateFile(content.Name) {
+			continue
+		}
+		data, err := a.githubAPI.GetFileContents(content.Path)
+		if err != nil {
+			return nil, "", err
+		}
+		template, err := issuetemplate.Parse(content.Name, data)
+		if err != nil {
+			return nil, "", err
+		}
+		templates = append(templates, template)
+	}
+	return templates, "github", nil
+}
+
+// checkoutIsTarget returns true if the current directory is in a checkout whose origin is the target repository, so
+// the files in the checkout belong to the repository that issues are being created in.
+func (a *Actions) checkoutIsTarget() bool {
+	repo, err := git.Open("")
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(repo.RemoteRepo("origin"), a.githubAPI.FullName())
+}
+
+func (a *Actions) findTemplate(name string) (*issuetemplate.Template, error) {
+	templates, _, err := a.loadTemplates()
+	if err != nil {
+		return nil, err
+	}
+	return issuetemplate.Find(templates, name)
+}
+
+// appendMissing appends any of the supplied items that are not already in the list.
+func appendMissing(list []string, items ...string) []string {
+	for _, item := range items {
+		found := false
+		for _, existing := range list {
+			if strings.EqualFold(existing, item) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func splitList(list string) []string {
+	items := []string{}
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// readBodyFile reads an issue body from the specified file. If the path is "-" the body is read from stdin.
+func readBodyFile(path string) (string, error) {
+	var body []byte
+	var err error
+	if path == "-" {
+		body, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		body, err = ioutil.ReadFile(path)
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(body), "\n"), nil
+}
+
+const dateFormat = "2006-01-02"
+
+// parseSince parses a point in time relative to now. The value can be a duration in minutes, hours, days or
+// weeks (i.e. "30m", "12h", "1d", "2w"), a date (2006-01-02), or an RFC3339 timestamp.
+func parseSince(value string, now time.Time) (time.Time, error) {
+	units := map[byte]time.Duration{
+		'm': time.Minute,
+		'h': time.Hour,
+		'd': 24 * time.Hour,
+		'w': 7 * 24 * time.Hour,
+	}
+	if len(value) > 1 {
+		if unit, ok := units[value[len(value)-1]]; ok {
+			if count, err := strconv.Atoi(value[:len(value)-1]); err == nil && count >= 0 {
+				return now.Add(-time.Duration(count) * unit), nil
+			}
+		}
+	}
+	if date, err := time.ParseInLocation(dateFormat, value, time.Local); err == nil {
+		return date, nil
+	}
+	if timestamp, err := time.Parse(time.RFC3339, value); err == nil {
+		return timestamp, nil
+	}
+	return time.Time{}, fmt.Errorf("'%v' is not a valid duration (i.e. 1d, 12h) or date (i.e. 2006-01-02)", value)
+}
+
+// relativeTime describes t relative to now (i.e. "3 hours ago").
+func relativeTime(t, now time.Time) string {
+	elapsed := now.Sub(t)
+	plural := func(count int, unit string) string {
+		if count == 1 {
+			return fmt.Sprintf("1 %v ago", unit)
+		}
+		return fmt.Sprintf("%v %vs ago", count, unit)
+	}
+	switch {
+	case elapsed < time.Minute:
+		return "just now"
+	case elapsed < time.Hour:
+		return plural(int(elapsed/time.Minute), "minute")
+	case elapsed < 24*time.Hour:
+		return plural(int(elapsed/time.Hour), "hour")
+	case elapsed < 30*24*time.Hour:
+		return plural(int(elapsed/(24*time.Hour)), "day")
+	case elapsed < 365*24*time.Hour:
+		return plural(int(elapsed/(30*24*time.Hour)), "month")
+	}
+	return plural(int(elapsed/(365*24*time.Hour)), "year")
 }
 
 func pr(str string, length int) string {
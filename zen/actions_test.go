@@ -0,0 +1,137 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/eltorocorp/zencli/zen/command"
+	"github.com/eltorocorp/zencli/zen/github"
+)
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2018, 3, 14, 15, 9, 26, 0, time.Local)
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"30m", now.Add(-30 * time.Minute)},
+		{"12h", now.Add(-12 * time.Hour)},
+		{"1d", now.Add(-24 * time.Hour)},
+		{"2w", now.Add(-14 * 24 * time.Hour)},
+		{"0d", now},
+		{"2018-01-31", time.Date(2018, 1, 31, 0, 0, 0, 0, time.Local)},
+		{"2018-01-31T10:00:00Z", time.Date(2018, 1, 31, 10, 0, 0, 0, time.UTC)},
+		{"2018-01-31T10:00:00-05:00", time.Date(2018, 1, 31, 15, 0, 0, 0, time.UTC)},
+	}
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			got, err := parseSince(test.value, now)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !got.Equal(test.want) {
+				t.Errorf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+
+	for _, value := range []string{"", "d", "1", "-1d", "1y", "1.5h", "d1", "2018-02-30", "yesterday"} {
+		t.Run(value, func(t *testing.T) {
+			if _, err := parseSince(value, now); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
+
+func TestRelativeTime(t *testing.T) {
+	now := time.Date(2018, 3, 14, 15, 0, 0, 0, time.UTC)
+	tests := []struct {
+		elapsed time.Duration
+		want    string
+	}{
+		{0, "just now"},
+		{59 * time.Second, "just now"},
+		{time.Minute, "1 minute ago"},
+		{59 * time.Minute, "59 minutes ago"},
+		{time.Hour, "1 hour ago"},
+		{23 * time.Hour, "23 hours ago"},
+		{24 * time.Hour, "1 day ago"},
+		{29 * 24 * time.Hour, "29 days ago"},
+		{30 * 24 * time.Hour, "1 month ago"},
+		{364 * 24 * time.Hour, "12 months ago"},
+		{365 * 24 * time.Hour, "1 year ago"},
+		{3 * 365 * 24 * time.Hour, "3 years ago"},
+	}
+	for _, test := range tests {
+		t.Run(test.want, func(t *testing.T) {
+			if got := relativeTime(now.Add(-test.elapsed), now); got != test.want {
+				t.Errorf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestIssueReference(t *testing.T) {
+	a := &Actions{githubAPI: github.New("", "zencli", "eltorocorp")}
+	tests := []struct {
+		ref  command.IssueRef
+		want string
+	}{
+		{command.IssueRef{Number: 12}, "#12"},
+		{command.IssueRef{Repo: "zencli", Number: 12}, "#12"},
+		{command.IssueRef{Owner: "EltoroCorp", Repo: "ZenCLI", Number: 12}, "#12"},
+		{command.IssueRef{Repo: "api", Number: 12}, "eltorocorp/api#12"},
+		{command.IssueRef{Owner: "someone", Repo: "zencli", Number: 12}, "someone/zencli#12"},
+	}
+	for _, test := range tests {
+		t.Run(test.want, func(t *testing.T) {
+			if got := a.issueReference(test.ref); got != test.want {
+				t.Errorf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestSprintMilestone(t *testing.T) {
+	now := time.Date(2018, 3, 14, 15, 0, 0, 0, time.Local)
+	milestone := func(title string, days int) *github.Milestone {
+		due := time.Date(2018, 3, 14+days, 8, 0, 0, 0, time.Local)
+		return &github.Milestone{Title: title, DueOn: &due}
+	}
+	undated := &github.Milestone{Title: "Someday"}
+	tests := []struct {
+		name       string
+		milestones []*github.Milestone
+		want       string
+	}{
+		{"next", []*github.Milestone{milestone("Overdue", -7), milestone("Later", 14), milestone("Next", 7), undated}, "Next"},
+		{"due today", []*github.Milestone{milestone("Overdue", -1), milestone("Today", 0)}, "Today"},
+		{"all overdue", []*github.Milestone{milestone("Older", -14), milestone("Overdue", -7)}, "Overdue"},
+		{"overdue before undated", []*github.Milestone{undated, milestone("Overdue", -7)}, "Overdue"},
+		{"undated", []*github.Milestone{undated, {Title: "Other"}}, "Someday"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := sprintMilestone(test.milestones, now); got.Title != test.want {
+				t.Errorf("expected %v, got %v", test.want, got.Title)
+			}
+		})
+	}
+}
+
+func TestCommentsSince(t *testing.T) {
+	since := time.Date(2018, 3, 14, 12, 0, 0, 0, time.UTC)
+	comment := func(id int, created time.Duration) *github.Comment {
+		return &github.Comment{ID: id, CreatedAt: since.Add(created), UpdatedAt: since.Add(time.Hour)}
+	}
+	comments := []*github.Comment{comment(1, -48*time.Hour), comment(2, 0), comment(3, 30*time.Minute)}
+	got := []int{}
+	for _, comment := range commentsSince(comments, since) {
+		got = append(got, comment.ID)
+	}
+	if want := []int{2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
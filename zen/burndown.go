@@ -0,0 +1,340 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eltorocorp/zencli/zen/github"
+	"github.com/eltorocorp/zencli/zen/zenhub"
+)
+
+const (
+	// burndownHeight is the number of rows in the burndown chart.
+	burndownHeight = 15
+	// burndownMaxWidth is the most columns the burndown chart has. Longer periods show several days per column.
+	burndownMaxWidth = 60
+)
+
+// burndownIssue is an issue being burned down, with its estimate.
+type burndownIssue struct {
+	issue  *github.Issue
+	points int
+}
+
+// burndownDay is the work remaining at the end of a day.
+type burndownDay struct {
+	date   time.Time
+	ideal  float64
+	closed int
+	// remaining is only known for days that have started.
+	remaining int
+	known     bool
+}
+
+// burndown is the series of days in a milestone or release.
+type burndown struct {
+	name  string
+	total int
+	days  []*burndownDay
+}
+
+// Burndown charts the points remaining each day of the specified milestone or ZenHub release against the ideal
+// line, or prints the series as CSV. The name is looked up as a milestone first, then as the title or ID of a
+// release. If no name is supplied, the open milestone with the nearest due date is used.
+func (a *Actions) Burndown(name, output string) error {
+	if name == "" {
+		milestone, err := a.findSprintMilestone("")
+		if err != nil {
+			return err
+		}
+		return a.milestoneBurndown(milestone, output)
+	}
+	milestone, err := a.githubAPI.FindMilestone(name)
+	if err != nil {
+		return err
+	}
+	if milestone != nil {
+		return a.milestoneBurndown(milestone, output)
+	}
+	release, err := a.zenHubAPI.FindRelease(name)
+	if err != nil {
+		return err
+	}
+	if release != nil {
+		return a.releaseBurndown(release, output)
+	}
+	return fmt.Errorf("'%v' is not a milestone or a ZenHub release of %v", name, a.githubAPI.RepoName)
+}
+
+// milestoneBurndown burns down the issues in a milestone, which starts on the start date set in ZenHub (or when it
+// was created) and ends on its due date.
+func (a *Actions) milestoneBurndown(milestone *github.Milestone, output string) error {
+	start, err := a.zenHubAPI.GetMilestoneStartDate(milestone.Number)
+	if err != nil {
+		return err
+	}
+	if start == nil {
+		start = &milestone.CreatedAt
+	}
+	end := milestone.DueOn
+	if end == nil {
+		end = milestone.ClosedAt
+	}
+	if end == nil {
+		return fmt.Errorf("milestone '%v' has no due date to burn down to", milestone.Title)
+	}
+
+	if output != "csv" {
+		fmt.Printf("Fetching issues in %v", milestone.Title)
+	}
+	githubIssues, err := a.githubAPI.GetIssuesForMilestone(milestone.Number)
+	if err != nil {
+		return err
+	}
+	issues, err := a.burndownIssues(githubIssues)
+	if err != nil {
+		return err
+	}
+	return printBurndown(newBurndown(milestone.Title, *start, *end, issues, time.Now()), output)
+}
+
+// releaseBurndown burns down the issues in a ZenHub release, which may be in any of its repositories, from its start
+// date to its desired end date.
+func (a *Actions) releaseBurndown(release *zenhub.Release, output string) error {
+	if release.StartDate == nil || release.DesiredEndDate == nil {
+		return fmt.Errorf("release '%v' needs a start date and a desired end date to burn down to", release.Title)
+	}
+	if output != "csv" {
+		fmt.Printf("Fetching issues in %v", release.Title)
+	}
+	releaseIssues, err := a.zenHubAPI.GetReleaseIssues(release.ID)
+	if err != nil {
+		return err
+	}
+	repoID, err := a.githubAPI.GetRepoID()
+	if err != nil {
+		return err
+	}
+
+	byRepo := map[int][]int{}
+	repoIDs := []int{}
+	for _, releaseIssue := range releaseIssues {
+		if _, ok := byRepo[releaseIssue.RepoID]; !ok {
+			repoIDs = append(repoIDs, releaseIssue.RepoID)
+		}
+		byRepo[releaseIssue.RepoID] = append(byRepo[releaseIssue.RepoID], releaseIssue.IssueNumber)
+	}
+	issues := []*burndownIssue{}
+	for _, id := range repoIDs {
+		actions := a
+		if id != *repoID {
+			repository, err := a.githubAPI.GetRepoByID(id)
+			if err != nil {
+				return err
+			}
+			repoActions, err := a.ForRepo(repository.Owner.Login, repository.Name)
+			if err != nil {
+				return err
+			}
+			actions = repoActions.(*Actions)
+		}
+		githubIssues := []*github.Issue{}
+		for _, number := range byRepo[id] {
+			issue, err := actions.githubAPI.GetIssue(number)
+			if err != nil {
+				return err
+			}
+			githubIssues = append(githubIssues, issue)
+		}
+		repoIssues, err := actions.burndownIssues(githubIssues)
+		if err != nil {
+			return err
+		}
+		issues = append(issues, repoIssues...)
+	}
+	return printBurndown(newBurndown(release.Title, *release.StartDate, *release.DesiredEndDate, issues, time.Now()), output)
+}
+
+// burndownIssues pairs the issues (leaving out pull requests) with their estimates. The estimates of open issues
+// come from the board; closed issues are not on the board, so their estimates are fetched individually.
+func (a *Actions) burndownIssues(githubIssues []*github.Issue) ([]*burndownIssue, error) {
+	pipelines, err := a.zenHubAPI.GetPipelines()
+	if err != nil {
+		return nil, err
+	}
+	estimates := map[int]int{}
+	for _, pipeline := range pipelines.List {
+		for _, issue := range pipeline.Issues {
+			estimates[issue.IssueNumber] = issue.Estimate.Value
+		}
+	}
+
+	issues := []*burndownIssue{}
+	for _, issue := range githubIssues {
+		if issue.IsPullRequest() {
+			continue
+		}
+		points, err := a.estimate(estimates, issue.Number)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, &burndownIssue{issue: issue, points: points})
+	}
+	return issues, nil
+}
+
+// newBurndown returns the points remaining at the end of each day from start to end, up to now. The scope is taken
+// to be the issues that are in the milestone or release now, since github does not record when they were added.
+// Issues that were closed before the start are burned down on the first day.
+func newBurndown(name string, start, end time.Time, issues []*burndownIssue, now time.Time) *burndown {
+	startDay := startOfDay(start.Local())
+	count := dayIndex(startDay, end.Local()) + 1
+	if count < 1 {
+		count = 1
+	}
+	today := dayIndex(startDay, now.Local())
+
+	result := &burndown{name: name}
+	closed := make([]int, count)
+	for _, issue := range issues {
+		result.total += issue.points
+		if issue.issue.State != github.StateClosed || issue.issue.ClosedAt == nil {
+			continue
+		}
+		day := dayIndex(startDay, issue.issue.ClosedAt.Local())
+		if day < 0 {
+			day = 0
+		}
+		if day < count {
+			closed[day] += issue.points
+		}
+	}
+
+	remaining := result.total
+	for i := 0; i < count; i++ {
+		ideal := float64(result.total)
+		if count > 1 {
+			ideal = float64(result.total) * float64(count-1-i) / float64(count-1)
+		}
+		remaining -= closed[i]
+		result.days = append(result.days, &burndownDay{
+			date:      startDay.AddDate(0, 0, i),
+			ideal:     ideal,
+			closed:    closed[i],
+			remaining: remaining,
+			known:     i <= today,
+		})
+	}
+	return result
+}
+
+// startOfDay returns midnight on the day that t is in.
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// dayIndex returns the number of days from the start day to the day that t is in.
+func dayIndex(startDay, t time.Time) int {
+	// Round the days, since a day that changes to or from daylight saving time is an hour shorter or longer.
+	return int(math.Round(startOfDay(t).Sub(startDay).Hours() / 24))
+}
+
+func printBurndown(series *burndown, output string) error {
+	if output == "csv" {
+		return writeBurndownCSV(series)
+	}
+	first, last := series.days[0], series.days[len(series.days)-1]
+	fmt.Printf("\rBurndown for %v (%v to %v)\n", series.name, first.date.Format(dateFormat), last.date.Format(dateFormat))
+	if series.total == 0 {
+		fmt.Println("None of the issues are estimated.")
+		return nil
+	}
+
+	// Each column is a day, or every few days if the period is too long to fit. The last day is always shown.
+	step := (len(series.days) + burndownMaxWidth - 2) / (burndownMaxWidth - 1)
+	columns := []*burndownDay{}
+	for i := 0; i < len(series.days); i += step {
+		columns = append(columns, series.days[i])
+	}
+	if columns[len(columns)-1] != last {
+		columns = append(columns, last)
+	}
+	row := func(value float64) int {
+		return int(math.Round(value / float64(series.total) * (burndownHeight - 1)))
+	}
+
+	labelWidth := len(strconv.Itoa(series.total))
+	for r := burndownHeight - 1; r >= 0; r-- {
+		label, axis := "", "│"
+		switch r {
+		case burndownHeight - 1:
+			label, axis = strconv.Itoa(series.total), "┤"
+		case 0:
+			label, axis = "0", "┤"
+		}
+		line := []string{}
+		for _, day := range columns {
+			switch {
+			case day.known && row(float64(day.remaining)) == r:
+				line = append(line, "●")
+			case row(day.ideal) == r:
+				line = append(line, "·")
+			default:
+				line = append(line, " ")
+			}
+		}
+		fmt.Printf("%*v %v%v\n", labelWidth, label, axis, strings.TrimRight(strings.Join(line, ""), " "))
+	}
+	fmt.Printf("%*v └%v\n", labelWidth, "", strings.Repeat("─", len(columns)))
+	startLabel, endLabel := first.date.Format("01-02"), last.date.Format("01-02")
+	gap := len(columns) - len(startLabel) - len(endLabel)
+	if gap < 1 {
+		gap = 1
+	}
+	fmt.Printf("%*v  %v%v%v\n", labelWidth, "", startLabel, strings.Repeat(" ", gap), endLabel)
+	fmt.Printf("%*v  ● remaining  · ideal\n", labelWidth, "")
+
+	var current *burndownDay
+	for _, day := range series.days {
+		if day.known {
+			current = day
+		}
+	}
+	fmt.Println()
+	if current == nil {
+		fmt.Printf("%v points, starting %v.\n", series.total, first.date.Format(dateFormat))
+		return nil
+	}
+	fmt.Printf("%v of %v points remaining", current.remaining, series.total)
+	difference := float64(current.remaining) - current.ideal
+	switch {
+	case difference >= 0.5:
+		fmt.Printf(", %.0f behind the ideal line", difference)
+	case difference <= -0.5:
+		fmt.Printf(", %.0f ahead of the ideal line", -difference)
+	default:
+		fmt.Printf(", on the ideal line")
+	}
+	fmt.Printf(" (as of %v).\n", current.date.Format(dateFormat))
+	return nil
+}
+
+// writeBurndownCSV writes a row for each day. The remaining points are left empty for days that have not started.
+func writeBurndownCSV(series *burndown) error {
+	w := csv.NewWriter(os.Stdout)
+	w.Write([]string{"date", "ideal_points", "remaining_points", "closed_points"})
+	for _, day := range series.days {
+		remaining, closed := "", ""
+		if day.known {
+			remaining, closed = strconv.Itoa(day.remaining), strconv.Itoa(day.closed)
+		}
+		w.Write([]string{day.date.Format(dateFormat), strconv.FormatFloat(day.ideal, 'f', 1, 64), remaining, closed})
+	}
+	w.Flush()
+	return w.Error()
+}
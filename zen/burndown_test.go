@@ -0,0 +1,106 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/eltorocorp/zencli/zen/github"
+)
+
+func TestNewBurndown(t *testing.T) {
+	at := func(day, hour int) time.Time { return time.Date(2018, 1, day, hour, 0, 0, 0, time.Local) }
+	issue := func(points int, closedDay int) *burndownIssue {
+		githubIssue := &github.Issue{State: github.StateOpen}
+		if closedDay != 0 {
+			closedAt := at(closedDay, 15)
+			githubIssue.State, githubIssue.ClosedAt = github.StateClosed, &closedAt
+		}
+		return &burndownIssue{issue: githubIssue, points: points}
+	}
+
+	tests := []struct {
+		name       string
+		start, end time.Time
+		now        time.Time
+		issues     []*burndownIssue
+		total      int
+		ideal      []float64
+		remaining  []int
+		known      int
+	}{
+		{
+			name:  "finished",
+			start: at(1, 9), end: at(5, 7), now: at(20, 12),
+			issues:    []*burndownIssue{issue(4, 2), issue(2, 4), issue(2, 0)},
+			total:     8,
+			ideal:     []float64{8, 6, 4, 2, 0},
+			remaining: []int{8, 4, 4, 2, 2},
+			known:     5,
+		},
+		{
+			name:  "in progress",
+			start: at(1, 9), end: at(5, 7), now: at(3, 12),
+			issues:    []*burndownIssue{issue(4, 2), issue(4, 0)},
+			total:     8,
+			ideal:     []float64{8, 6, 4, 2, 0},
+			remaining: []int{8, 4, 4, 4, 4},
+			known:     3,
+		},
+		{
+			name:  "closed before the start or after the end",
+			start: at(3, 9), end: at(4, 7), now: at(20, 12),
+			issues:    []*burndownIssue{issue(1, 1), issue(2, 9), issue(4, 0)},
+			total:     7,
+			ideal:     []float64{7, 0},
+			remaining: []int{6, 6},
+			known:     2,
+		},
+		{
+			name:  "not started",
+			start: at(10, 9), end: at(11, 7), now: at(3, 12),
+			issues:    []*burndownIssue{issue(3, 0)},
+			total:     3,
+			ideal:     []float64{3, 0},
+			remaining: []int{3, 3},
+			known:     0,
+		},
+		{
+			name:  "ends before it starts",
+			start: at(10, 9), end: at(8, 7), now: at(20, 12),
+			issues:    []*burndownIssue{issue(3, 0)},
+			total:     3,
+			ideal:     []float64{3},
+			remaining: []int{3},
+			known:     1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			series := newBurndown("test", test.start, test.end, test.issues, test.now)
+			if series.total != test.total {
+				t.Errorf("expected a total of %v, got %v", test.total, series.total)
+			}
+			ideal, remaining, known := []float64{}, []int{}, 0
+			for i, day := range series.days {
+				if !day.date.Equal(startOfDay(test.start).AddDate(0, 0, i)) {
+					t.Errorf("expected day %v to be %v days after the start, got %v", i, i, day.date)
+				}
+				ideal = append(ideal, day.ideal)
+				remaining = append(remaining, day.remaining)
+				if day.known {
+					known++
+				}
+			}
+			if !reflect.DeepEqual(ideal, test.ideal) {
+				t.Errorf("expected the ideal line %v, got %v", test.ideal, ideal)
+			}
+			if !reflect.DeepEqual(remaining, test.remaining) {
+				t.Errorf("expected the remaining points %v, got %v", test.remaining, remaining)
+			}
+			if known != test.known {
+				t.Errorf("expected %v known days, got %v", test.known, known)
+			}
+		})
+	}
+}
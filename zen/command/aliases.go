@@ -0,0 +1,157 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/eltorocorp/zencli/zen/shellwords"
+)
+
+// maxAliasDepth limits how deeply aliases can refer to other aliases.
+const maxAliasDepth = 10
+
+// AliasLister can optionally be implemented by Actions to supply user-defined aliases, keyed by name. If it is
+// implemented, aliases are expanded before the args are parsed.
+//
+// An alias is a command line, without the leading "zen". $1, $2 (and so on) are replaced with the arguments that
+// follow the alias, $@ is replaced with all of them, and $$ is a literal "$". If the alias does not refer to its
+// arguments, they are appended to the end of it. Commands can be chained with an unquoted "&&", in which case they
+// are run in order until one of them fails.
+type AliasLister interface {
+	Aliases() (map[string]string, error)
+}
+
+// IsCommand returns true if the word is the first keyword of a command, and so cannot be used as an alias.
+func IsCommand(word string) bool {
+	for _, command := range registry {
+		if command.Keywords[0] == word {
+			return true
+		}
+	}
+	return false
+}
+
+// expandAliases expands the aliases in the supplied args, and returns the args for each of the commands to run.
+// Commands take precedence over aliases, so a warning is printed if an alias has the same name as the command that is
+// being run (aliases like this can only be added by editing the config file by hand).
+func expandAliases(args []string, actions Actions) ([][]string, error) {
+	lister, ok := actions.(AliasLister)
+	if !ok || len(args) == 0 {
+		return [][]string{args}, nil
+	}
+	aliases, err := lister.Aliases()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := aliases[args[0]]; ok && IsCommand(args[0]) {
+		fmt.Fprintf(os.Stderr, "warning: the alias '%v' is ignored, since '%v' is a zen command; rename it to use it\n", args[0], args[0])
+	}
+	return expand(args, aliases, 0)
+}
+
+func expand(args []string, aliases map[string]string, depth int) ([][]string, error) {
+	if len(args) == 0 || IsCommand(args[0]) {
+		return [][]string{args}, nil
+	}
+	name := args[0]
+	definition, ok := aliases[name]
+	if !ok {
+		return [][]string{args}, nil
+	}
+	if depth >= maxAliasDepth {
+		return nil, fmt.Errorf("alias '%v' is nested too deeply; does it refer to itself?", name)
+	}
+
+	line, used, err := substitute(name, definition, args[1:])
+	if err != nil {
+		return nil, err
+	}
+	words, open, _ := shellwords.Words(line)
+	if open {
+		return nil, fmt.Errorf("unable to expand alias '%v': %v", name, shellwords.ErrUnterminatedQuote)
+	}
+
+	commands := [][]string{{}}
+	for _, word := range words {
+		if word.Text == "&&" && !word.Quoted {
+			commands = append(commands, []string{})
+			continue
+		}
+		commands[len(commands)-1] = append(commands[len(commands)-1], word.Text)
+	}
+	if !used {
+		commands[len(commands)-1] = append(commands[len(commands)-1], args[1:]...)
+	}
+
+	expanded := [][]string{}
+	for _, command := range commands {
+		if len(command) == 0 {
+			return nil, fmt.Errorf("alias '%v' contains an empty command", name)
+		}
+		nested, err := expand(command, aliases, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		expanded = append(expanded, nested...)
+	}
+	return expanded, nil
+}
+
+// substitute replaces the positional parameters in the alias definition with the supplied args, quoted so that
+// they are split back into the same args. used is true if the definition refers to any of the args.
+func substitute(name, definition string, args []string) (line string, used bool, err error) {
+	b := &strings.Builder{}
+	for i := 0; i < len(definition); i++ {
+		if definition[i] != '$' || i+1 == len(definition) {
+			b.WriteByte(definition[i])
+			continue
+		}
+		next := definition[i+1]
+		switch {
+		case next == '$':
+			b.WriteByte('$')
+			i++
+		case next == '@':
+			quoted := []string{}
+			for _, arg := range args {
+				quoted = append(quoted, shellwords.Quote(arg))
+			}
+			b.WriteString(strings.Join(quoted, " "))
+			used = true
+			i++
+		case next >= '1' && next <= '9':
+			end := i + 1
+			for end < len(definition) && definition[end] >= '0' && definition[end] <= '9' {
+				end++
+			}
+			position, _ := strconv.Atoi(definition[i+1 : end])
+			if position > len(args) {
+				return "", false, fmt.Errorf("alias '%v' expects at least %v argument(s)", name, position)
+			}
+			b.WriteString(shellwords.Quote(args[position-1]))
+			used = true
+			i = end - 1
+		default:
+			b.WriteByte('$')
+		}
+	}
+	return b.String(), used, nil
+}
+
+// joinCommand joins the words of a command supplied as several args. A command supplied as a single arg is
+// returned as is, so that any quoting in it is kept. A separate "&&" arg is kept as the operator that chains commands.
+func joinCommand(words []string) string {
+	if len(words) == 1 {
+		return words[0]
+	}
+	quoted := []string{}
+	for _, word := range words {
+		if word != "&&" {
+			word = shellwords.Quote(word)
+		}
+		quoted = append(quoted, word)
+	}
+	return strings.Join(quoted, " ")
+}
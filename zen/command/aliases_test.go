@@ -0,0 +1,102 @@
+package command
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSubstitute(t *testing.T) {
+	tests := []struct {
+		definition string
+		args       []string
+		want       string
+		used       bool
+	}{
+		{"list only me", []string{"--no-prs"}, "list only me", false},
+		{"pick up $1 && move $1 to review", []string{"12"}, "pick up 12 && move 12 to review", true},
+		{"move $2 to $1", []string{"in progress", "12"}, `move 12 to "in progress"`, true},
+		{"comment $@", []string{"12", "it's done"}, `comment 12 "it's done"`, true},
+		{"comment $@", nil, "comment ", true},
+		{"comment 1 $1", []string{"&&"}, `comment 1 "&&"`, true},
+		{"comment 1 costs $$1 or $ or $x$", []string{"a"}, "comment 1 costs $1 or $ or $x$", false},
+		{"show $10", []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10"}, "show 10", true},
+	}
+	for _, test := range tests {
+		t.Run(test.definition, func(t *testing.T) {
+			line, used, err := substitute("test", test.definition, test.args)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if line != test.want || used != test.used {
+				t.Errorf("expected %v (%v), got %v (%v)", test.want, test.used, line, used)
+			}
+		})
+	}
+
+	_, _, err := substitute("grab", "pick up $1 && move $2 to review", []string{"12"})
+	if err == nil || err.Error() != "alias 'grab' expects at least 2 argument(s)" {
+		t.Errorf("expected a missing argument error, got %v", err)
+	}
+}
+
+func TestExpand(t *testing.T) {
+	aliases := map[string]string{
+		"wip":     "list only me",
+		"grab":    "pick up $1 && move $1 to review",
+		"both":    "grab $1 && wip",
+		"note":    `comment $1 "a && b"`,
+		"start":   "show 1",
+		"loop":    "loop",
+		"empty":   "show 1 &&",
+		"unended": `comment 1 "abc`,
+	}
+	tests := []struct {
+		args []string
+		want [][]string
+	}{
+		{[]string{"show", "12"}, [][]string{{"show", "12"}}},
+		{[]string{"wip", "--no-prs"}, [][]string{{"list", "only", "me", "--no-prs"}}},
+		{[]string{"grab", "12"}, [][]string{{"pick", "up", "12"}, {"move", "12", "to", "review"}}},
+		{[]string{"both", "12"}, [][]string{{"pick", "up", "12"}, {"move", "12", "to", "review"}, {"list", "only", "me"}}},
+		{[]string{"note", "12"}, [][]string{{"comment", "12", "a && b"}}},
+		{[]string{"grab", "&&"}, [][]string{{"pick", "up", "&&"}, {"move", "&&", "to", "review"}}},
+		{[]string{"start", "12"}, [][]string{{"start", "12"}}},
+	}
+	for _, test := range tests {
+		t.Run(test.args[0], func(t *testing.T) {
+			got, err := expand(test.args, aliases, 0)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+
+	errors := map[string]string{
+		"loop":    "alias 'loop' is nested too deeply; does it refer to itself?",
+		"empty":   "alias 'empty' contains an empty command",
+		"unended": "unable to expand alias 'unended': the line ends inside a quoted string",
+	}
+	for name, want := range errors {
+		if _, err := expand([]string{name}, aliases, 0); err == nil || err.Error() != want {
+			t.Errorf("expected %q, got %v", want, err)
+		}
+	}
+}
+
+func TestJoinCommand(t *testing.T) {
+	tests := []struct {
+		words []string
+		want  string
+	}{
+		{[]string{`pick up $1 && move "$1" to review`}, `pick up $1 && move "$1" to review`},
+		{[]string{"pick", "up", "$1", "&&", "move", "$1", "to", "in progress"}, `pick up $1 && move $1 to "in progress"`},
+	}
+	for _, test := range tests {
+		if got := joinCommand(test.words); got != test.want {
+			t.Errorf("expected %v, got %v", test.want, got)
+		}
+	}
+}
@@ -1,23 +1,97 @@
 package command
 
+import (
+	"fmt"
+	"strings"
+)
+
 // The API for the command
 type API struct {
-	args          []string
-	actions       Actions
-	currentSymbol string
-	symbolIndex   int
+	args    []string
+	actions Actions
+	// commands are the args for each command to run, once any aliases have been expanded.
+	commands [][]string
+	err      error
 }
 
 // The Actions that the command is able to execute.
 type Actions interface {
-	Help()
-	Close(issue int) error
-	Create(title, pipeline string) error
+	Help(topic string) error
+	Man(format, dir string) error
+	Close(issue int, reason string, duplicateOf IssueRef) error
+	Comment(issue int, body, bodyFile string) error
+	Comments(issue int, since string, last int) error
+	Complete(words []string) error
+	Completion(shell string) error
+	Create(title, pipeline string, options CreateOptions) error
+	Burndown(name, output string) error
+	CreateMilestone(title, due string) error
+	CycleTime(options MetricsOptions) error
 	Open(issue int) error
 	Drop(issue int) error
-	List(backlog bool, login string) error
+	EditBody(issue int, body string, clear bool) error
+	EditTitle(issue int, title string) error
+	Finish(draft bool) error
+	InstallHook(force bool) error
+	CommitMsgHook(file string) error
+	Label(issue int, operation string, labels []string) error
+	Labels() error
+	ListAliases() error
+	SetAlias(name, definition string) error
+	DeleteAlias(name string) error
+	List(options ListOptions) error
+	Milestones() error
 	Move(issue int, pipeline string) error
 	PickUp(issue int) error
+	Run(file string, dryRun bool) error
+	SetMilestone(issue int, milestone string) error
+	Shell() error
+	Show(issue int) error
+	Sprint(milestone string) error
+	Standup(since, login, output string) error
+	Start(issue int, force bool) error
+	SyncLabels(file string, dryRun bool) error
+	Templates() error
+	Velocity(last int, by string) error
+	Workspaces() error
+}
+
+// PipelineLister can optionally be implemented by Actions to list the names of the pipelines on the board. If it is
+// implemented, pipeline arguments are checked before a command runs, and misspelled pipelines are reported with a
+// suggestion.
+type PipelineLister interface {
+	PipelineNames() ([]string, error)
+}
+
+// ListOptions are the filters that can be applied when listing issues.
+type ListOptions struct {
+	Backlog bool
+	Login   string
+	// NoPRs omits pull requests, and OnlyPRs omits everything else.
+	NoPRs   bool
+	OnlyPRs bool
+}
+
+// MetricsOptions are the options for the metrics reports.
+type MetricsOptions struct {
+	Since string
+	// From and To are the pipelines that a period starts and ends in.
+	From   string
+	To     string
+	Output string
+}
+
+// CreateOptions are the optional fields that can be supplied when creating an issue. The epic can be in another
+// repository.
+type CreateOptions struct {
+	Body      string
+	BodyFile  string
+	Labels    []string
+	Assignees []string
+	Milestone string
+	Estimate  *int
+	Epic      IssueRef
+	Template  string
 }
 
 // New returns a command API capable of parsing the supplied args and execution the appropriate commands.
@@ -25,82 +99,96 @@ func New(args []string, actions Actions) *API {
 	if len(args) == 0 {
 		panic("args cannot be emptyt")
 	}
-	return &API{
-		args:          args,
-		currentSymbol: args[0],
-		symbolIndex:   0,
-		actions:       actions,
+	c := &API{
+		args:    args,
+		actions: actions,
 	}
+	c.commands, c.err = expandAliases(args[1:], actions)
+	return c
 }
 
-// Execute parses the supplied args and runs the appropriate commands based on the parsed command.
+// Execute parses the supplied args and runs the appropriate commands based on the parsed command. If the args are
+// an alias for several commands, they are run in order until one of them fails.
 func (c *API) Execute() error {
-	var (
-		issue    int
-		pipeline string
-		login    string
-		title    string
-		backlog  bool
-	)
-
-	for _, symbol := range c.args {
-		if symbol == string(HELP) {
-			c.actions.Help()
-			return nil
+	if c.err != nil {
+		return c.err
+	}
+	for _, args := range c.commands {
+		err := c.execute(args)
+		if err != nil {
+			return err
 		}
 	}
-	if !c.nextSymbol() {
-		return c.parserError()
+	return nil
+}
+
+func (c *API) execute(args []string) error {
+	command, values, positions, err := parse(args)
+	if err != nil {
+		return err
+	}
+	err = command.resolveCurrentIssues(c.actions, values)
+	if err != nil {
+		return err
 	}
-	if c.expectToken(CLOSE) &&
-		c.nextSymbol() &&
-		c.expectCurrentSymbolInt(&issue) {
-		return c.actions.Close(issue)
-	} else if c.expectToken(OPEN) &&
-		c.nextSymbol() &&
-		c.expectCurrentSymbolInt(&issue) {
-		return c.actions.Open(issue)
-	} else if c.expectToken(DROP) &&
-		c.nextSymbol() &&
-		c.expectCurrentSymbolInt(&issue) {
-		return c.actions.Drop(issue)
-	} else if c.expectToken(CREATE) &&
-		c.nextSymbol() &&
-		c.expectCurrentSymbolString(&title) &&
-		c.nextSymbol() &&
-		c.expectToken(AS) &&
-		c.nextSymbol() &&
-		c.expectCurrentSymbolString(&pipeline) {
-		return c.actions.Create(title, pipeline)
-	} else if c.expectToken(LIST) {
-		backlog = false
-		for c.nextSymbol() {
-			if c.expectToken(ONLY) &&
-				c.nextSymbol() &&
-				c.expectCurrentSymbolString(&login) {
-				continue
-			} else if c.expectToken(BACKLOG) {
-				backlog = true
-				continue
+
+	actions, err := c.actionsFor(command, values)
+	if err != nil {
+		return err
+	}
+	err = validatePipelines(actions, args, command, values, positions)
+	if err != nil {
+		return err
+	}
+	return command.Run(actions, values)
+}
+
+// actionsFor returns the actions for the repository that the command's primary issue argument refers to.
+func (c *API) actionsFor(command *Command, values Values) (Actions, error) {
+	owner, repo := command.repository(values)
+	if repo == "" {
+		return c.actions, nil
+	}
+	switcher, ok := c.actions.(RepoSwitcher)
+	if !ok {
+		return nil, fmt.Errorf("issues in other repositories (%v) are not supported", repo)
+	}
+	return switcher.ForRepo(owner, repo)
+}
+
+// validatePipelines checks that any pipeline arguments exist on the board, if the actions are able to list the
+// pipelines. Misspelled pipelines are reported as parse errors with the closest matching pipeline.
+func validatePipelines(actions Actions, args []string, command *Command, values Values, positions map[string]int) error {
+	lister, ok := actions.(PipelineLister)
+	if !ok {
+		return nil
+	}
+	for name, argumentType := range command.arguments() {
+		if argumentType != PipelineArgument || !values.Has(name) {
+			continue
+		}
+		pipelines, err := lister.PipelineNames()
+		if err != nil {
+			return err
+		}
+		pipeline := values.String(name)
+		found := false
+		for _, existing := range pipelines {
+			if strings.EqualFold(existing, pipeline) {
+				found = true
+				break
 			}
-			return c.parserError()
 		}
-		return c.actions.List(backlog, login)
-	} else if c.expectToken(MOVE) &&
-		c.nextSymbol() &&
-		c.expectCurrentSymbolInt(&issue) &&
-		c.nextSymbol() &&
-		c.ignoreToken(TO) {
-		if c.expectCurrentSymbolString(&pipeline) {
-			return c.actions.Move(issue, pipeline)
+		if found {
+			continue
+		}
+		message := fmt.Sprintf("unknown pipeline '%v'", pipeline)
+		if suggestion := suggest(pipeline, pipelines); suggestion != "" {
+			message += fmt.Sprintf("; did you mean '%v'?", suggestion)
+		} else {
+			message += fmt.Sprintf("; the pipelines are: %v", strings.Join(pipelines, ", "))
 		}
-		return c.parserError()
-	} else if c.expectToken(PICK) &&
-		c.nextSymbol() &&
-		c.expectToken(UP) &&
-		c.nextSymbol() &&
-		c.expectCurrentSymbolInt(&issue) {
-		return c.actions.PickUp(issue)
+		return &ParseError{Args: args, Position: positions[name], Message: message}
 	}
-	return c.parserError()
+	return nil
 }
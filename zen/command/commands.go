@@ -0,0 +1,617 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+)
+
+func init() {
+	Register(&Command{
+		Keywords: []string{"alias", "list"},
+		Summary:  "Lists your aliases.",
+		Run: func(actions Actions, values Values) error {
+			return actions.ListAliases()
+		},
+	})
+
+	Register(&Command{
+		Keywords: []string{"alias", "set"},
+		Syntax:   []Element{Arg("name", TextArgument), Args("command", TextArgument)},
+		Summary:  "Defines an alias for a command.",
+		Details: "$1, $2 (and so on) in the command are replaced with the arguments that follow the\n" +
+			"alias, and $@ with all of them. Commands can be chained with \"&&\" (quote it to pass\n" +
+			"a literal \"&&\"). An alias cannot have the name of a zen command. Aliases are stored\n" +
+			"in the [alias] section of your config file ($ZENCLI_CONFIG, or zen/config.toml in\n" +
+			"your user config directory).",
+		Examples: []Example{
+			{Description: "To list your own issues with \"zen wip\":", Command: "zen alias set wip \"list only me\""},
+			{Description: "To pick up an issue and move it to 'review' with \"zen grab 123\":", Command: "zen alias set grab 'pick up $1 && move $1 to review'"},
+		},
+		Run: func(actions Actions, values Values) error {
+			return actions.SetAlias(values.String("name"), joinCommand(values.Strings("command")))
+		},
+	})
+
+	Register(&Command{
+		Keywords: []string{"alias", "delete"},
+		Syntax:   []Element{Arg("name", TextArgument)},
+		Summary:  "Deletes an alias.",
+		Run: func(actions Actions, values Values) error {
+			return actions.DeleteAlias(values.String("name"))
+		},
+	})
+
+	Register(&Command{
+		Keywords: []string{"burndown"},
+		Syntax: []Element{
+			Optional(Arg("name", MilestoneArgument)),
+			Clauses(
+				Clause("Prints the chart (the default), or the series as CSV (a row per day).", Keyword("--output"), Choice("output", "chart", "csv")),
+			),
+		},
+		Summary: "Charts the points remaining each day of a milestone or ZenHub release against the ideal line.",
+		Details: "The name is looked up as a milestone first, then as the title or ID of a\n" +
+			"release. Defaults to the open milestone that is due next. A milestone runs\n" +
+			"from its ZenHub start date (or when it was created) to its due date, and a release\n" +
+			"from its start date to its desired end date.",
+		Examples: []Example{
+			{Description: "To see how the current sprint is going:", Command: "zen burndown"},
+			{Description: "To see whether the 2.0 release is on track:", Command: "zen burndown \"2.0\""},
+			{Description: "To chart a milestone in a spreadsheet:", Command: "zen burndown \"Sprint 12\" --output csv > burndown.csv"},
+		},
+		Run: func(actions Actions, values Values) error {
+			return actions.Burndown(values.String("name"), values.String("output"))
+		},
+	})
+
+	Register(&Command{
+		Keywords: []string{"close"},
+		Syntax: []Element{
+			Arg("issue", IssueArgument),
+			Optional(Keyword("as"), Arg("reason", TextArgument), Optional(Keyword("of"), Arg("original", IssueArgument))),
+		},
+		Summary: "Changes the status of the specified issue to closed.",
+		Details: "The reason can be \"completed\" (the default), \"not_planned\" or\n" +
+			"\"duplicate of <issue>\".",
+		Examples: []Example{
+			{Description: "To close issue number 123:", Command: "zen close 123"},
+			{Description: "To close issue 123 as a duplicate of issue 99:", Command: "zen close 123 as duplicate of 99"},
+		},
+		Run: func(actions Actions, values Values) error {
+			return actions.Close(values.Int("issue"), values.String("reason"), values.Issue("original"))
+		},
+	})
+
+	Register(&Command{
+		Keywords: []string{"comment"},
+		Syntax: []Element{
+			Arg("issue", IssueArgument),
+			Optional(Arg("comment", TextArgument)),
+			Clauses(
+				Clause("Reads the comment from the specified file (\"-\" reads from stdin).", Keyword("--body-file"), Arg("file", TextArgument)),
+			),
+		},
+		Summary:        "Adds a comment to the specified issue.",
+		Details:        "If the comment is omitted, your editor is opened to compose it.",
+		OmittableIssue: true,
+		Examples: []Example{
+			{Description: "To add a comment to issue 123 from a deployment script:", Command: "echo \"Deployed to production.\" | zen comment 123 --body-file -"},
+		},
+		Run: func(actions Actions, values Values) error {
+			return actions.Comment(values.Int("issue"), values.String("comment"), values.String("file"))
+		},
+	})
+
+	Register(&Command{
+		Keywords: []string{"comments"},
+		Syntax: []Element{
+			Arg("issue", IssueArgument),
+			Clauses(
+				Clause("Only shows comments since a duration (i.e. 12h, 1d, 2w) or date (i.e. 2018-01-31).", Keyword("--since"), Arg("since", TextArgument)),
+				Clause("Only shows the last <count> comments.", Keyword("--last"), Arg("count", NumberArgument)),
+			),
+		},
+		Summary:        "Prints the comments on the specified issue.",
+		OmittableIssue: true,
+		Examples: []Example{
+			{Description: "To read the last 5 comments on issue 123:", Command: "zen comments 123 --last 5"},
+		},
+		Run: func(actions Actions, values Values) error {
+			if values.Has("count") && values.Int("count") <= 0 {
+				return fmt.Errorf("the count for --last must be greater than zero")
+			}
+			return actions.Comments(values.Int("issue"), values.String("since"), values.Int("count"))
+		},
+	})
+
+	Register(&Command{
+		Keywords: []string{"completion"},
+		Syntax:   []Element{Choice("shell", "bash", "zsh", "fish")},
+		Summary:  "Prints the completion script for the specified shell.",
+		Examples: []Example{
+			{Description: "To enable completion in bash, add this to your ~/.bashrc:", Command: "source <(zen completion bash)"},
+			{Description: "To enable completion in fish:", Command: "zen completion fish > ~/.config/fish/completions/zen.fish"},
+		},
+		Run: func(actions Actions, values Values) error {
+			return actions.Completion(values.String("shell"))
+		},
+	})
+
+	Register(&Command{
+		Keywords: []string{"__complete"},
+		Syntax:   []Element{Optional(Args("words", TextArgument))},
+		Summary:  "Prints the completions for the last of the supplied words. Used by the completion scripts.",
+		Hidden:   true,
+		Run: func(actions Actions, values Values) error {
+			return actions.Complete(values.Strings("words"))
+		},
+	})
+
+	Register(&Command{
+		Keywords: []string{"create"},
+		Syntax: []Element{
+			Optional(Arg("title", TextArgument)),
+			Clauses(
+				Clause("The pipeline to create the issue in. Defaults to the backlog.", Keyword("as"), Arg("pipeline", PipelineArgument)),
+				Clause("The body of the issue.", Keyword("with"), Keyword("body"), Arg("body", TextArgument)),
+				Clause("Applies the labels, assignees and body of the specified issue template.", Keyword("--template"), Arg("template", TextArgument)),
+				Clause("Reads the body of the issue from the specified file (\"-\" reads from stdin).", Keyword("--body-file"), Arg("file", TextArgument)),
+				Clause("A comma separated list of labels to apply to the issue.", Keyword("labeled"), Arg("labels", LabelsArgument)),
+				Clause("A comma separated list of logins (or \"me\") to assign to the issue.", Keyword("assigned"), Keyword("to"), Arg("logins", LoginsArgument)),
+				Clause("The milestone to add the issue to.", Keyword("in"), Keyword("milestone"), Arg("milestone", MilestoneArgument)),
+				Clause("The ZenHub estimate for the issue.", Keyword("estimate"), Arg("estimate", NumberArgument)),
+				Clause("The ZenHub epic to add the issue to.", Keyword("in"), Keyword("epic"), Arg("epic", IssueArgument)),
+			),
+		},
+		Summary: "Creates a new issue.",
+		Details: "If the title is omitted, the issue is composed in your editor.",
+		Examples: []Example{
+			{Description: "To create a new issue in the 'prioritized' pipeline:", Command: "zen create \"There's clearly a bug in this code\" as prioritized"},
+			{Description: "To create a new issue in the 'in progress' pipeline:", Command: "zen create \"This is another issue.\" as \"in progress\""},
+			{Description: "To create a new issue with a body, labels and an estimate, and assign it to yourself:", Command: "zen create \"Fix the login page\" with body \"It is broken.\" labeled bug,frontend assigned to me estimate 3"},
+			{Description: "To compose a new issue in your editor, starting in the 'prioritized' pipeline:", Command: "zen create as prioritized"},
+			{Description: "To compose a new bug report in your editor from the repository's \"bug\" issue template:", Command: "zen create --template bug"},
+		},
+		Run: func(actions Actions, values Values) error {
+			pipeline := values.String("pipeline")
+			if pipeline == "" {
+				pipeline = "backlog"
+			}
+			options := CreateOptions{
+				Body:      values.String("body"),
+				BodyFile:  values.String("file"),
+				Labels:    values.Strings("labels"),
+				Assignees: values.Strings("logins"),
+				Milestone: values.String("milestone"),
+				Epic:      values.Issue("epic"),
+				Template:  values.String("template"),
+			}
+			if values.Has("estimate") {
+				estimate := values.Int("estimate")
+				options.Estimate = &estimate
+			}
+			return actions.Create(values.String("title"), pipeline, options)
+		},
+	})
+
+	Register(&Command{
+		Keywords: []string{"drop"},
+		Syntax:   []Element{Arg("issue", IssueArgument)},
+		Summary:  "Removes you as an assignee on the specified issue.",
+		Run: func(actions Actions, values Values) error {
+			return actions.Drop(values.Int("issue"))
+		},
+	})
+
+	Register(&Command{
+		Keywords:       []string{"edit"},
+		Syntax:         []Element{Arg("issue", IssueArgument), Keyword("title"), Arg("title", TextArgument)},
+		Summary:        "Changes the title of the specified issue.",
+		OmittableIssue: true,
+		Run: func(actions Actions, values Values) error {
+			return actions.EditTitle(values.Int("issue"), values.String("title"))
+		},
+	})
+
+	Register(&Command{
+		Keywords: []string{"edit"},
+		Syntax: []Element{
+			Arg("issue", IssueArgument),
+			Keyword("body"),
+			Optional(Arg("body", TextArgument)),
+			Clauses(
+				Clause("Removes the body of the issue.", Flag("--clear")),
+			),
+		},
+		Summary:        "Changes the body of the specified issue.",
+		Details:        "If the body is omitted, your editor is opened to edit the current body.",
+		OmittableIssue: true,
+		Run: func(actions Actions, values Values) error {
+			return actions.EditBody(values.Int("issue"), values.String("body"), values.Bool("--clear"))
+		},
+	})
+
+	Register(&Command{
+		Keywords: []string{"finish"},
+		Syntax: []Element{
+			Clauses(
+				Clause("Opens the pull request as a draft.", Flag("--draft")),
+			),
+		},
+		Summary: "Finishes work on the checked out branch's issue.",
+		Details: "Opens a pull request for the branch against the default branch, titled after the issue\n" +
+			"and closing it, requests reviews, moves the issue to the review pipeline, and connects\n" +
+			"the pull request to the issue in ZenHub. The branch must already be pushed. The\n" +
+			"reviewers (a comma separated list of logins and org/team names) and the pipeline are\n" +
+			"set by reviewers and review_pipeline (default \"Review\") in the [workflow] section of\n" +
+			"your config file.",
+		Examples: []Example{
+			{Description: "To push the branch and open a draft pull request for it:", Command: "git push -u origin HEAD && zen finish --draft"},
+		},
+		Run: func(actions Actions, values Values) error {
+			return actions.Finish(values.Bool("--draft"))
+		},
+	})
+
+	Register(&Command{
+		Keywords: []string{"help"},
+		Syntax:   []Element{Optional(Args("command", TextArgument))},
+		Summary:  "Displays the usage information for zen or for the specified command.",
+		Details:  "The usage information for a command includes its parameters and examples.",
+		Examples: []Example{
+			{Description: "To show the usage information and examples for the create command:", Command: "zen help create"},
+		},
+		Run: func(actions Actions, values Values) error {
+			return actions.Help(strings.Join(values.Strings("command"), " "))
+		},
+	})
+
+	Register(&Command{
+		Keywords: []string{"hook", "install"},
+		Syntax: []Element{
+			Clauses(
+				Clause("Replaces an existing commit-msg hook that zen did not install.", Flag("--force")),
+			),
+		},
+		Summary: "Installs a commit-msg hook that makes every commit refer to an issue.",
+		Details: "The hook is installed in the local repository, and runs \"zen hook commit-msg\".",
+		Run: func(actions Actions, values Values) error {
+			return actions.InstallHook(values.Bool("--force"))
+		},
+	})
+
+	Register(&Command{
+		Keywords: []string{"hook", "commit-msg"},
+		Syntax:   []Element{Arg("file", TextArgument)},
+		Summary:  "Checks that the commit message in the specified file refers to an issue (i.e. #123).",
+		Details: "If it does not, a reference to the checked out branch's issue is added, or the commit\n" +
+			"is rejected if the branch does not name one. If check_commit_issues is true in the\n" +
+			"[workflow] section of your config file, references to issues that are closed or in\n" +
+			"the backlog are rejected too, using a board that is cached for a few minutes.",
+		Run: func(actions Actions, values Values) error {
+			return actions.CommitMsgHook(values.String("file"))
+		},
+	})
+
+	Register(&Command{
+		Keywords: []string{"label"},
+		Syntax: []Element{
+			Arg("issue", IssueArgument),
+			Choice("operation", "add", "remove", "set"),
+			Optional(Args("labels", LabelsArgument)),
+		},
+		Summary:        "Adds, removes or replaces the labels on the specified issue.",
+		OmittableIssue: true,
+		Examples: []Example{
+			{Description: "To add the \"bug\" and \"urgent\" labels to issue 123:", Command: "zen label 123 add bug urgent"},
+		},
+		Run: func(actions Actions, values Values) error {
+			return actions.Label(values.Int("issue"), values.String("operation"), values.Strings("labels"))
+		},
+	})
+
+	Register(&Command{
+		Keywords: []string{"labels"},
+		Summary:  "Lists the labels defined for the current repository.",
+		Run: func(actions Actions, values Values) error {
+			return actions.Labels()
+		},
+	})
+
+	Register(&Command{
+		Keywords: []string{"labels", "sync"},
+		Syntax: []Element{
+			Arg("file", TextArgument),
+			Clauses(
+				Clause("Prints the changes without applying them.", Flag("--dry-run")),
+			),
+		},
+		Summary: "Makes the labels for the current repository match the specified YAML file.",
+		Details: "Labels that are not in the file are deleted.",
+		Examples: []Example{
+			{Description: "To preview the changes needed to make the repository's labels match labels.yml:", Command: "zen labels sync labels.yml --dry-run"},
+		},
+		Run: func(actions Actions, values Values) error {
+			return actions.SyncLabels(values.String("file"), values.Bool("--dry-run"))
+		},
+	})
+
+	Register(&Command{
+		Keywords: []string{"list"},
+		Syntax: []Element{
+			Clauses(
+				Clause("The backlog pipeline is omitted from results unless \"--backlog\" is supplied.", Flag("--backlog")),
+				Clause("The list of issues will be filtered to only include the specified github login.\n"+
+					"When this option is supplied, unassigned issues are still displayed.\n"+
+					"If \"me\" is supplied as the login, the current authenticated user's login is used.",
+					Keyword("only"), Arg("login", LoginArgument)),
+				Clause("Pull requests are omitted from the results.", Flag("--no-prs")),
+				Clause("Only pull requests are included in the results.", Flag("--only-prs")),
+			),
+		},
+		Summary: "Lists all of the pipelines and issues for the current repository.",
+		Details: "If the repository is in a ZenHub workspace, the issues from every repository in\n" +
+			"the workspace are listed, prefixed with their repository's name. Pull requests are\n" +
+			"marked, with whether they are drafts, their CI status and their review state.",
+		Examples: []Example{
+			{Description: "To list only my issues:", Command: "zen list only me"},
+			{Description: "To list the open pull requests, with their CI and review status:", Command: "zen list --only-prs"},
+		},
+		Run: func(actions Actions, values Values) error {
+			if values.Bool("--no-prs") && values.Bool("--only-prs") {
+				return fmt.Errorf("--no-prs and --only-prs cannot be used together")
+			}
+			return actions.List(ListOptions{
+				Backlog: values.Bool("--backlog"),
+				Login:   values.String("login"),
+				NoPRs:   values.Bool("--no-prs"),
+				OnlyPRs: values.Bool("--only-prs"),
+			})
+		},
+	})
+
+	Register(&Command{
+		Keywords: []string{"man"},
+		Syntax:   []Element{Optional(Choice("format", "roff", "markdown")), Optional(Arg("dir", TextArgument))},
+		Summary:  "Prints the man page for zen, or writes a page for each command to a directory.",
+		Details:  "Pages are roff (the default) or markdown.",
+		Examples: []Example{
+			{Description: "To install the man pages for zen:", Command: "zen man /usr/local/share/man/man1"},
+			{Description: "To generate Markdown documentation in the docs directory:", Command: "zen man markdown docs"},
+		},
+		Run: func(actions Actions, values Values) error {
+			return actions.Man(values.String("format"), values.String("dir"))
+		},
+	})
+
+	Register(&Command{
+		Keywords: []string{"metrics", "cycle-time"},
+		Syntax: []Element{
+			Clauses(
+				Clause("Reports the issues finished since a duration (i.e. 30d, 2w) or date (i.e. 2018-01-31). Defaults to 30d.", Keyword("--since"), Arg("since", TextArgument)),
+				Clause("The pipeline that work starts in. Defaults to the in_progress_pipeline workflow setting.", Keyword("--from"), Arg("from", TextArgument)),
+				Clause("The pipeline that work finishes in, or \"closed\" (the default) for when the issue was closed.", Keyword("--to"), Arg("to", TextArgument)),
+				Clause("Prints the report as a table (the default), as CSV (a row per issue) or as JSON.", Keyword("--output"), Choice("output", "table", "csv", "json")),
+			),
+		},
+		Summary: "Reports the cycle time and lead time of each issue that finished recently.",
+		Details: "The cycle time is from the \"from\" pipeline to the \"to\" pipeline, and the lead time is\n" +
+			"from being created. The p50, p85 and p95 are reported for all of the issues, and for\n" +
+			"the time spent in each pipeline before each transition. The history of each issue is\n" +
+			"rebuilt from its ZenHub events. Issues that never entered the \"from\" pipeline are only\n" +
+			"counted in the lead time.",
+		Examples: []Example{
+			{Description: "To see how long issues took from 'in progress' to 'done' over the last sprint:", Command: "zen metrics cycle-time --since 2w --from \"in progress\" --to done"},
+			{Description: "To analyze the cycle times in a spreadsheet:", Command: "zen metrics cycle-time --since 90d --output csv > cycle-time.csv"},
+		},
+		Run: func(actions Actions, values Values) error {
+			return actions.CycleTime(MetricsOptions{
+				Since:  values.String("since"),
+				From:   values.String("from"),
+				To:     values.String("to"),
+				Output: values.String("output"),
+			})
+		},
+	})
+
+	Register(&Command{
+		Keywords: []string{"milestone"},
+		Syntax:   []Element{Arg("issue", IssueArgument), Arg("milestone", MilestoneArgument)},
+		Summary:  "Adds the specified issue to the specified milestone.",
+		Examples: []Example{
+			{Description: "To add issue 123 to the \"Sprint 12\" milestone:", Command: "zen milestone 123 \"Sprint 12\""},
+		},
+		Run: func(actions Actions, values Values) error {
+			return actions.SetMilestone(values.Int("issue"), values.String("milestone"))
+		},
+	})
+
+	Register(&Command{
+		Keywords: []string{"milestone", "create"},
+		Syntax:   []Element{Arg("title", TextArgument), Optional(Keyword("due"), Arg("date", TextArgument))},
+		Summary:  "Creates a new milestone, optionally due on the specified date (i.e. 2018-01-31).",
+		Examples: []Example{
+			{Description: "To create a milestone for the next sprint:", Command: "zen milestone create \"Sprint 12\" due 2018-02-01"},
+		},
+		Run: func(actions Actions, values Values) error {
+			return actions.CreateMilestone(values.String("title"), values.String("date"))
+		},
+	})
+
+	Register(&Command{
+		Keywords: []string{"milestones"},
+		Summary:  "Lists the milestones for the current repository with their issue counts and due dates.",
+		Run: func(actions Actions, values Values) error {
+			return actions.Milestones()
+		},
+	})
+
+	Register(&Command{
+		Keywords:       []string{"move"},
+		Syntax:         []Element{Arg("issue", IssueArgument), Optional(Keyword("to")), Arg("pipeline", PipelineArgument)},
+		Summary:        "Moves the specified issue from its current pipeline to the specified pipeline.",
+		OmittableIssue: true,
+		Examples: []Example{
+			{Description: "To move issue 999 to the \"in progress\" pipeline:", Command: "zen move 999 to \"in progress\""},
+		},
+		Run: func(actions Actions, values Values) error {
+			return actions.Move(values.Int("issue"), values.String("pipeline"))
+		},
+	})
+
+	Register(&Command{
+		Keywords: []string{"open"},
+		Syntax:   []Element{Arg("issue", IssueArgument)},
+		Summary:  "Changes the status of the specified issue to open.",
+		Run: func(actions Actions, values Values) error {
+			return actions.Open(values.Int("issue"))
+		},
+	})
+
+	Register(&Command{
+		Keywords: []string{"pick", "up"},
+		Syntax:   []Element{Arg("issue", IssueArgument)},
+		Summary:  "Adds you as an assignee on the specified issue.",
+		Run: func(actions Actions, values Values) error {
+			return actions.PickUp(values.Int("issue"))
+		},
+	})
+
+	Register(&Command{
+		Keywords: []string{"run"},
+		Syntax: []Element{
+			Arg("file", TextArgument),
+			Clauses(
+				Clause("Prints the requests that would change issues, instead of sending them.", Flag("--dry-run")),
+			),
+		},
+		Summary: "Runs the zen commands in the specified file, one per line.",
+		Details: "Use \"-\" to read the commands from stdin as they arrive. Lines starting with \"#\"\n" +
+			"are comments, NAME=value defines a variable that can be used as $NAME, and \"set -e\"\n" +
+			"stops the script at the first command that fails.",
+		Examples: []Example{
+			{Description: "To preview the requests that a sprint start script would send:", Command: "zen run sprint-start.zen --dry-run"},
+			{Description: "To move several issues to the 'prioritized' pipeline:", Command: "printf 'move %s to prioritized\\n' 12 15 21 | zen run -"},
+		},
+		Run: func(actions Actions, values Values) error {
+			return actions.Run(values.String("file"), values.Bool("--dry-run"))
+		},
+	})
+
+	Register(&Command{
+		Keywords: []string{"shell"},
+		Summary:  "Opens an interactive prompt that runs zen commands.",
+		Details: "The prompt has line editing, history and tab completion. Enter \"use repo <owner/name>\"\n" +
+			"to switch repositories, or \"exit\" to leave the shell.",
+		Run: func(actions Actions, values Values) error {
+			return actions.Shell()
+		},
+	})
+
+	Register(&Command{
+		Keywords: []string{"show"},
+		Syntax:   []Element{Arg("issue", IssueArgument)},
+		Summary:  "Shows the details of the specified issue and the pull requests linked to it.",
+		Details: "Pull requests are linked by ZenHub connections (\"connects #123\") or closing\n" +
+			"references (\"fixes #123\"), and are shown with their CI status and review state.",
+		OmittableIssue: true,
+		Examples: []Example{
+			{Description: "To see issue 123 and the state of its pull requests:", Command: "zen show 123"},
+		},
+		Run: func(actions Actions, values Values) error {
+			return actions.Show(values.Int("issue"))
+		},
+	})
+
+	Register(&Command{
+		Keywords: []string{"sprint"},
+		Syntax:   []Element{Optional(Arg("milestone", MilestoneArgument))},
+		Summary:  "Shows the issues in the specified milestone grouped by pipeline, with point totals.",
+		Details:  "Defaults to the open milestone that is due next.",
+		Run: func(actions Actions, values Values) error {
+			return actions.Sprint(values.String("milestone"))
+		},
+	})
+
+	Register(&Command{
+		Keywords: []string{"standup"},
+		Syntax: []Element{
+			Clauses(
+				Clause("Reports the activity since a duration (i.e. 12h, 1d, 3d) or date (i.e. 2018-01-31). Defaults to 1d.", Keyword("--since"), Arg("since", TextArgument)),
+				Clause("Reports the activity of the specified login instead of yours.", Keyword("--for"), Arg("login", LoginArgument)),
+				Clause("Prints the report as text (the default) or as Markdown, for pasting into chat.", Keyword("--output"), Choice("output", "text", "markdown")),
+			),
+		},
+		Summary: "Reports your recent activity, grouped into done, in progress and blocked.",
+		Details: "The activity is the issues you opened, closed, commented on, were assigned or moved\n" +
+			"between pipelines, and the pull requests you opened or reviewed.",
+		Examples: []Example{
+			{Description: "To report what you did since Friday morning, for pasting into chat:", Command: "zen standup --since 3d --output markdown"},
+		},
+		Run: func(actions Actions, values Values) error {
+			return actions.Standup(values.String("since"), values.String("login"), values.String("output"))
+		},
+	})
+
+	Register(&Command{
+		Keywords: []string{"start"},
+		Syntax: []Element{
+			Arg("issue", IssueArgument),
+			Clauses(
+				Clause("Starts the issue even if the working tree has uncommitted changes.", Flag("--force")),
+			),
+		},
+		Summary: "Starts work on the specified issue.",
+		Details: "Assigns you to the issue, moves it to the in progress pipeline, and creates and checks\n" +
+			"out a local branch for it (or checks out the branch if it already exists). The\n" +
+			"pipeline and the branch name are set by in_progress_pipeline (default \"In Progress\")\n" +
+			"and branch_template (default \"{{.Number}}-{{slug .Title}}\") in the [workflow] section\n" +
+			"of your config file.",
+		Examples: []Example{
+			{Description: "To start work on issue 123 on a branch named like 123-fix-the-login-page:", Command: "zen start 123"},
+		},
+		Run: func(actions Actions, values Values) error {
+			return actions.Start(values.Int("issue"), values.Bool("--force"))
+		},
+	})
+
+	Register(&Command{
+		Keywords: []string{"templates"},
+		Summary:  "Lists the issue templates in .github/ISSUE_TEMPLATE for the current repository.",
+		Details:  "Templates are read from the local checkout if it has any, otherwise from github.",
+		Run: func(actions Actions, values Values) error {
+			return actions.Templates()
+		},
+	})
+
+	Register(&Command{
+		Keywords: []string{"velocity"},
+		Syntax: []Element{
+			Clauses(
+				Clause("Reports the last <count> milestones or weeks. Defaults to 6.", Keyword("--last"), Arg("count", NumberArgument)),
+				Clause("Reports the work completed per milestone (the default) or per week.", Keyword("--by"), Choice("by", "milestone", "week")),
+			),
+		},
+		Summary: "Reports the story points and issues completed in each of the last milestones or weeks.",
+		Details: "The points are shown with a bar chart, the averages and the trend. Only finished\n" +
+			"milestones (closed or past their due date) and full weeks are included.",
+		Examples: []Example{
+			{Description: "To see the velocity of the last 6 milestones:", Command: "zen velocity"},
+			{Description: "To see how many points were completed each week for the last quarter:", Command: "zen velocity --last 13 --by week"},
+		},
+		Run: func(actions Actions, values Values) error {
+			return actions.Velocity(values.Int("count"), values.String("by"))
+		},
+	})
+
+	Register(&Command{
+		Keywords: []string{"workspaces"},
+		Summary:  "Lists the ZenHub workspaces that the current repository belongs to.",
+		Details: "The repositories in each workspace are listed too. The workspace marked with \"*\" is\n" +
+			"the one whose board is used; set ZENCLI_WORKSPACE to a workspace's name or ID to\n" +
+			"select another.",
+		Run: func(actions Actions, values Values) error {
+			return actions.Workspaces()
+		},
+	})
+}
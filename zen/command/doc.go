@@ -1,2 +1,5 @@
-// Package command command contains a simple lexer/parser that parses the supplied arguments and executes the appropriate actions.
+// Package command command contains a simple parser that parses the supplied arguments and executes the appropriate actions.
+//
+// Each command is declared once (see Register) with its keywords, typed arguments and optional clauses, along with
+// the action that it runs. The parser, the usage information and completion are all driven by those declarations.
 package command
@@ -0,0 +1,295 @@
+package command
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	programSummary     = "a small CLI for interacting with zenhub/github"
+	programDescription = "zen is a small utility for interacting with ZenHub boards through a simple command line interface."
+)
+
+// Example is an example of how to use a command.
+type Example struct {
+	// Description describes what the example does (i.e. "To close issue number 123:").
+	Description string
+	// Command is the example command line, without the leading "$ ".
+	Command string
+}
+
+// Topics returns the help topics, which are the first keywords of the registered commands, sorted.
+func Topics() []string {
+	topics := []string{}
+	seen := map[string]bool{}
+	for _, command := range sortedByName() {
+		if !seen[command.Keywords[0]] {
+			seen[command.Keywords[0]] = true
+			topics = append(topics, command.Keywords[0])
+		}
+	}
+	return topics
+}
+
+// lookup returns the commands for the specified topic. A topic matches every command whose name is the topic, or
+// starts with the topic (so "labels" matches both "labels" and "labels sync").
+func lookup(topic string) ([]*Command, error) {
+	topic = strings.Join(strings.Fields(topic), " ")
+	matches := []*Command{}
+	for _, command := range sortedByName() {
+		if command.Name() == topic || strings.HasPrefix(command.Name(), topic+" ") {
+			matches = append(matches, command)
+		}
+	}
+	if len(matches) > 0 {
+		return matches, nil
+	}
+
+	message := fmt.Sprintf("there is no help for '%v'", topic)
+	if suggestion := suggest(topic, Topics()); suggestion != "" {
+		message += fmt.Sprintf("; did you mean '%v'?", suggestion)
+	}
+	return nil, fmt.Errorf("%v\nRun `zen help` for a list of commands.", message)
+}
+
+// Usage returns the usage information for zen, including all of the registered commands and their examples.
+func Usage() string {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "NAME\n    zen -- %v\n\n", programSummary)
+	fmt.Fprintf(b, "SYNOPSIS\n    zen <command> [parameters]\n\n")
+	fmt.Fprintf(b, "DESCRIPTION\n    %v\n\n", programDescription)
+	b.WriteString("COMMANDS\n")
+	for _, command := range sortedByName() {
+		writeCommandUsage(b, command)
+	}
+	b.WriteString("\n")
+	writeExamples(b, sortedByName())
+	return b.String()
+}
+
+// Help returns the detailed usage information for the commands in the specified topic.
+func Help(topic string) (string, error) {
+	commands, err := lookup(topic)
+	if err != nil {
+		return "", err
+	}
+
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "NAME\n    zen %v -- %v\n\n", commands[0].Name(), commands[0].shortDescription())
+	b.WriteString("SYNOPSIS\n")
+	for _, command := range commands {
+		fmt.Fprintf(b, "    zen %v\n", command.Usage())
+	}
+	b.WriteString("\nDESCRIPTION\n")
+	for _, command := range commands {
+		writeCommandUsage(b, command)
+	}
+	b.WriteString("\n")
+	writeExamples(b, commands)
+	return b.String(), nil
+}
+
+// ManPage returns the roff man page for the specified topic. If the topic is empty, the overview page for zen is
+// returned.
+func ManPage(topic string) (string, error) {
+	b := &strings.Builder{}
+	if topic == "" {
+		b.WriteString(".TH \"ZEN\" \"1\" \"\" \"zen\" \"zen manual\"\n")
+		fmt.Fprintf(b, ".SH NAME\nzen \\- %v\n", roffEscape(programSummary))
+		b.WriteString(".SH SYNOPSIS\n.B zen\n.I <command>\n[parameters]\n")
+		fmt.Fprintf(b, ".SH DESCRIPTION\n%v\n", roffEscape(programDescription))
+		b.WriteString(".SH COMMANDS\n")
+		for _, command := range sortedByName() {
+			fmt.Fprintf(b, ".TP\n.B %v\n%v\n", roffEscape(command.Usage()), roffEscape(command.description()))
+		}
+		b.WriteString(".SH SEE ALSO\n")
+		references := []string{}
+		for _, topic := range Topics() {
+			references = append(references, fmt.Sprintf(".BR zen\\-%v (1)", topic))
+		}
+		b.WriteString(strings.Join(references, ",\n") + "\n")
+		return b.String(), nil
+	}
+
+	commands, err := lookup(topic)
+	if err != nil {
+		return "", err
+	}
+	page := "zen-" + strings.Replace(topic, " ", "-", -1)
+	fmt.Fprintf(b, ".TH \"%v\" \"1\" \"\" \"zen\" \"zen manual\"\n", strings.ToUpper(page))
+	fmt.Fprintf(b, ".SH NAME\n%v \\- %v\n", roffEscape(page), roffEscape(commands[0].shortDescription()))
+	b.WriteString(".SH SYNOPSIS\n.nf\n")
+	for _, command := range commands {
+		fmt.Fprintf(b, "\\fBzen %v\\fR %v\n", roffEscape(command.Name()), roffEscape(syntaxOf(command.Syntax)))
+	}
+	b.WriteString(".fi\n.SH DESCRIPTION\n")
+	for _, command := range commands {
+		fmt.Fprintf(b, ".TP\n.B zen %v\n%v\n", roffEscape(command.Usage()), roffEscape(command.description()))
+		for _, clause := range command.clauses() {
+			fmt.Fprintf(b, ".RS\n.TP\n.B [%v]\n%v\n.RE\n", roffEscape(syntaxOf(clause.elements)), roffEscape(clause.description))
+		}
+	}
+	examples := examplesOf(commands)
+	if len(examples) > 0 {
+		b.WriteString(".SH EXAMPLES\n")
+		for _, example := range examples {
+			fmt.Fprintf(b, "%v\n.PP\n.RS 4\n.nf\n$ %v\n.fi\n.RE\n.PP\n", roffEscape(example.Description), roffEscape(example.Command))
+		}
+	}
+	b.WriteString(".SH SEE ALSO\n.BR zen (1)\n")
+	return b.String(), nil
+}
+
+// MarkdownPage returns the Markdown documentation for the specified topic. If the topic is empty, the overview
+// page for zen is returned, which links to the page for each topic.
+func MarkdownPage(topic string) (string, error) {
+	b := &strings.Builder{}
+	if topic == "" {
+		fmt.Fprintf(b, "# zen\n\n%v\n\n", programDescription)
+		b.WriteString("```\nzen <command> [parameters]\n```\n\n## Commands\n\n")
+		b.WriteString("| Command | Description |\n| --- | --- |\n")
+		for _, command := range sortedByName() {
+			fmt.Fprintf(b, "| [`zen %v`](zen-%v.md) | %v |\n", tableCell(command.Usage()), command.Keywords[0], tableCell(command.description()))
+		}
+		return b.String(), nil
+	}
+
+	commands, err := lookup(topic)
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprintf(b, "# zen %v\n\n", topic)
+	for _, command := range commands {
+		fmt.Fprintf(b, "```\nzen %v\n```\n\n%v\n\n", command.Usage(), strings.Replace(command.description(), "\n", " ", -1))
+		clauses := command.clauses()
+		if len(clauses) > 0 {
+			b.WriteString("| Parameter | Description |\n| --- | --- |\n")
+			for _, clause := range clauses {
+				fmt.Fprintf(b, "| `[%v]` | %v |\n", tableCell(syntaxOf(clause.elements)), tableCell(clause.description))
+			}
+			b.WriteString("\n")
+		}
+	}
+	examples := examplesOf(commands)
+	if len(examples) > 0 {
+		b.WriteString("## Examples\n\n")
+		for _, example := range examples {
+			fmt.Fprintf(b, "%v\n\n```\n$ %v\n```\n\n", example.Description, example.Command)
+		}
+	}
+	b.WriteString("See also [zen](zen.md).\n")
+	return b.String(), nil
+}
+
+// tableCell prepares text for a cell of a Markdown table: the text is kept on one line, and any "|" (i.e. between the
+// words of a choice) is escaped so that it does not end the cell, even inside a code span.
+func tableCell(text string) string {
+	return strings.NewReplacer("\n", " ", "|", `\|`).Replace(text)
+}
+
+func (c *Command) description() string {
+	if c.Details == "" {
+		return c.Summary
+	}
+	return c.Summary + "\n" + c.Details
+}
+
+func (c *Command) clauses() []*ClauseElement {
+	clauses := []*ClauseElement{}
+	for _, element := range c.Syntax {
+		if set, ok := element.(*clauseSet); ok {
+			clauses = append(clauses, set.clauses...)
+		}
+	}
+	return clauses
+}
+
+// sortedByName returns the commands that are not hidden, sorted by name.
+func sortedByName() []*Command {
+	sorted := []*Command{}
+	for _, command := range registry {
+		if !command.Hidden {
+			sorted = append(sorted, command)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Name() < sorted[j].Name()
+	})
+	return sorted
+}
+
+func examplesOf(commands []*Command) []Example {
+	examples := []Example{}
+	for _, command := range commands {
+		examples = append(examples, command.Examples...)
+	}
+	return examples
+}
+
+func writeCommandUsage(b *strings.Builder, command *Command) {
+	writeUsageEntry(b, usageIndent, command.Usage(), command.description())
+	clauses := command.clauses()
+	if len(clauses) == 0 {
+		return
+	}
+	b.WriteString(strings.Repeat(" ", usageClauseIndent) + "parameters:\n")
+	for _, clause := range clauses {
+		writeUsageEntry(b, usageClauseIndent, "["+syntaxOf(clause.elements)+"]", clause.description)
+	}
+}
+
+func writeExamples(b *strings.Builder, commands []*Command) {
+	examples := examplesOf(commands)
+	if len(examples) == 0 {
+		return
+	}
+	b.WriteString("EXAMPLES\n")
+	for i, example := range examples {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(b, "    %v\n\n        $ %v\n", example.Description, example.Command)
+	}
+}
+
+// writeUsageEntry writes the syntax at the specified indent, followed by the description aligned to the usage
+// column. If the syntax is too long, the description starts on the following line.
+func writeUsageEntry(b *strings.Builder, indent int, syntax, description string) {
+	line := strings.Repeat(" ", indent) + syntax
+	lines := strings.Split(description, "\n")
+	if len(line) >= usageColumn-1 {
+		b.WriteString(line + "\n")
+		line = ""
+	}
+	for _, descriptionLine := range lines {
+		b.WriteString(line + strings.Repeat(" ", usageColumn-len(line)) + descriptionLine + "\n")
+		line = ""
+	}
+}
+
+// roffEscape escapes text for use in a roff document. Lines are joined, since the description lines in the
+// command metadata are wrapped for the terminal rather than the man page.
+func roffEscape(text string) string {
+	text = strings.Replace(text, "\\", "\\e", -1)
+	text = strings.Replace(text, "-", "\\-", -1)
+	text = strings.Replace(text, "\n", " ", -1)
+	if strings.HasPrefix(text, ".") || strings.HasPrefix(text, "'") {
+		text = "\\&" + text
+	}
+	return text
+}
+
+// shortDescription returns the first sentence of the command's description, starting with a lower case letter and
+// without the trailing period.
+func (c *Command) shortDescription() string {
+	text := strings.Replace(c.description(), "\n", " ", -1)
+	if end := strings.Index(text, ". "); end >= 0 {
+		text = text[:end]
+	}
+	text = strings.TrimSuffix(text, ".")
+	if text == "" {
+		return text
+	}
+	return strings.ToLower(text[:1]) + text[1:]
+}
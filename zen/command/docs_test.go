@@ -0,0 +1,27 @@
+package command
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkdownPageEscapesChoices(t *testing.T) {
+	tests := []struct {
+		topic, want string
+	}{
+		{"", "| [`zen completion bash\\|zsh\\|fish`](zen-completion.md) |"},
+		{"completion", "zen completion bash|zsh|fish\n```"},
+		{"burndown", "| `[--output chart\\|csv]` |"},
+	}
+	for _, test := range tests {
+		t.Run(test.topic, func(t *testing.T) {
+			page, err := MarkdownPage(test.topic)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !strings.Contains(page, test.want) {
+				t.Errorf("expected the page to contain %q, got:\n%v", test.want, page)
+			}
+		})
+	}
+}
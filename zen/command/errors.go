@@ -0,0 +1,196 @@
+package command
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseError describes why the supplied arguments could not be parsed, and where.
+type ParseError struct {
+	// Args are the arguments that were parsed (excluding the program name).
+	Args []string
+	// Position is the index in Args of the argument that could not be parsed. If an argument was missing, the
+	// position is the length of Args.
+	Position int
+	// Message describes the problem.
+	Message string
+	// Topic is the help topic for the command that was being parsed, if it is known.
+	Topic string
+}
+
+// Error returns the message followed by the command line, with a caret under the argument that could not be parsed.
+func (e *ParseError) Error() string {
+	line := "zen"
+	caret := ""
+	for i, arg := range e.Args {
+		if i == e.Position {
+			caret = strings.Repeat(" ", len(line)+1) + strings.Repeat("^", len(quote(arg)))
+		}
+		line += " " + quote(arg)
+	}
+	if caret == "" {
+		caret = strings.Repeat(" ", len(line)+1) + "^"
+	}
+	help := "zen help"
+	if e.Topic != "" {
+		help += " " + e.Topic
+	}
+	return fmt.Sprintf("%v\n    %v\n    %v\nRun `%v` for usage information.", e.Message, line, caret, help)
+}
+
+// quote quotes an argument the way it would need to be typed in a shell, if it contains spaces or quotes.
+func quote(arg string) string {
+	if arg == "" || strings.ContainsAny(arg, " \t\"'") {
+		return strconv.Quote(arg)
+	}
+	return arg
+}
+
+// newParseError builds the error for args that did not match any command, from everything the parsers expected at
+// the furthest position that any of them reached.
+func newParseError(args []string, expectations []expectation) *ParseError {
+	if len(args) == 0 {
+		return &ParseError{Args: args, Message: "no command was supplied"}
+	}
+
+	position := 0
+	for _, e := range expectations {
+		if e.position > position {
+			position = e.position
+		}
+	}
+
+	expected := []string{}
+	keywords := []string{}
+	seen := map[string]bool{}
+	for _, e := range expectations {
+		if e.position != position {
+			continue
+		}
+		description := ""
+		switch {
+		case e.end:
+			description = "the end of the command"
+		case e.argument != nil:
+			description = fmt.Sprintf("<%v> (%v)", e.argument.name, e.argument.argumentType.describe())
+		default:
+			description = "'" + e.keyword + "'"
+			keywords = append(keywords, e.keyword)
+		}
+		if !seen[description] {
+			seen[description] = true
+			expected = append(expected, description)
+		}
+	}
+
+	if position == 0 {
+		message := fmt.Sprintf("unknown command '%v'", args[0])
+		if suggestion := suggest(args[0], keywords); suggestion != "" {
+			message += fmt.Sprintf("; did you mean '%v'?", suggestion)
+		}
+		return &ParseError{Args: args, Position: 0, Message: message}
+	}
+
+	description := expected[0]
+	if len(expected) > 1 {
+		description = "one of " + strings.Join(expected, ", ")
+	}
+	if position >= len(args) {
+		return &ParseError{
+			Args:     args,
+			Position: position,
+			Message:  fmt.Sprintf("argument %v is missing; expected %v", position+1, description),
+		}
+	}
+
+	message := fmt.Sprintf("argument %v ('%v') is not valid here; expected %v", position+1, args[position], description)
+	if suggestion := suggest(args[position], keywords); suggestion != "" {
+		message += fmt.Sprintf("; did you mean '%v'?", suggestion)
+	}
+	return &ParseError{Args: args, Position: position, Message: message}
+}
+
+func (t ArgumentType) describe() string {
+	switch t {
+	case IssueArgument:
+		return "an issue number or reference, i.e. 123, owner/repo#123, a github URL or . for the current branch's issue"
+	case PipelineArgument:
+		return "a pipeline name"
+	case LoginArgument:
+		return "a github login or \"me\""
+	case NumberArgument:
+		return "a number"
+	case ListArgument:
+		return "a comma separated list"
+	case LabelsArgument:
+		return "a comma separated list of labels"
+	case LoginsArgument:
+		return "a comma separated list of github logins"
+	case MilestoneArgument:
+		return "a milestone title"
+	}
+	return "text"
+}
+
+// suggest returns the candidate that is closest to the supplied word, if it is close enough to be a likely typo.
+// Otherwise it returns an empty string.
+func suggest(word string, candidates []string) string {
+	best := ""
+	bestDistance := 0
+	for _, candidate := range candidates {
+		distance := levenshtein(strings.ToLower(word), strings.ToLower(candidate))
+		if best == "" || distance < bestDistance {
+			best = candidate
+			bestDistance = distance
+		}
+	}
+
+	limit := len(word) / 3
+	if limit < 2 {
+		limit = 2
+	}
+	if best == "" || bestDistance == 0 || bestDistance > limit {
+		return ""
+	}
+	return best
+}
+
+// levenshtein returns the Levenshtein distance between a and b: the number of single character insertions,
+// deletions and substitutions needed to change a into b. Transpositions of adjacent characters (a common typo,
+// i.e. "lsit") also count as a single edit.
+func levenshtein(a, b string) int {
+	source := []rune(a)
+	target := []rune(b)
+	distances := make([][]int, len(source)+1)
+	for i := range distances {
+		distances[i] = make([]int, len(target)+1)
+		distances[i][0] = i
+	}
+	for j := range distances[0] {
+		distances[0][j] = j
+	}
+	for i := 1; i <= len(source); i++ {
+		for j := 1; j <= len(target); j++ {
+			cost := 1
+			if source[i-1] == target[j-1] {
+				cost = 0
+			}
+			distances[i][j] = min3(distances[i-1][j]+1, distances[i][j-1]+1, distances[i-1][j-1]+cost)
+			if i > 1 && j > 1 && source[i-1] == target[j-2] && source[i-2] == target[j-1] && distances[i-2][j-2]+1 < distances[i][j] {
+				distances[i][j] = distances[i-2][j-2] + 1
+			}
+		}
+	}
+	return distances[len(source)][len(target)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
@@ -0,0 +1,89 @@
+package command
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"list", "list", 0},
+		{"", "list", 4},
+		{"list", "", 4},
+		{"lst", "list", 1},
+		{"lisst", "list", 1},
+		{"lost", "list", 1},
+		{"lsit", "list", 1},
+		{"slit", "list", 2},
+		{"kitten", "sitting", 3},
+		{"milestone", "milestones", 1},
+		{"prògress", "progress", 1},
+	}
+	for _, test := range tests {
+		t.Run(test.a+" "+test.b, func(t *testing.T) {
+			if got := levenshtein(test.a, test.b); got != test.want {
+				t.Errorf("expected %v, got %v", test.want, got)
+			}
+			if got := levenshtein(test.b, test.a); got != test.want {
+				t.Errorf("expected the distance to be symmetric (%v), got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"list", "labels", "label", "milestone", "milestones", "move", "In Progress"}
+	tests := []struct {
+		word string
+		want string
+	}{
+		{"lsit", "list"},
+		{"lables", "labels"},
+		{"mve", "move"},
+		{"mlestones", "milestones"},
+		{"milestons", "milestone"}, // ties go to the first candidate
+		{"in progres", "In Progress"},
+		{"IN PROGRESS", ""},
+		{"list", ""},
+		{"xyz", ""},
+		{"stand", ""},
+		{"", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.word, func(t *testing.T) {
+			if got := suggest(test.word, candidates); got != test.want {
+				t.Errorf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+
+	if got := suggest("lsit", nil); got != "" {
+		t.Errorf("expected no suggestion without candidates, got %q", got)
+	}
+}
+
+func TestParseErrorSuggestions(t *testing.T) {
+	tests := []struct {
+		args string
+		want string
+	}{
+		{"lsit", "unknown command 'lsit'; did you mean 'list'?"},
+		{"close 12 ass x", "did you mean 'as'?"},
+		{"move 12 to", "argument 4 is missing; expected <pipeline>"},
+	}
+	for _, test := range tests {
+		t.Run(test.args, func(t *testing.T) {
+			_, _, _, err := parse(strings.Fields(test.args))
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			if !strings.Contains(err.Error(), test.want) {
+				t.Errorf("expected %q in %q", test.want, err.Error())
+			}
+		})
+	}
+}
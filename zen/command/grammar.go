@@ -0,0 +1,411 @@
+package command
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ArgumentType describes the values that an argument accepts.
+type ArgumentType string
+
+const (
+	// IssueArgument accepts an issue number or reference (see ParseIssueRef).
+	IssueArgument ArgumentType = "issue"
+	// PipelineArgument accepts the name of a ZenHub pipeline.
+	PipelineArgument ArgumentType = "pipeline"
+	// LoginArgument accepts a github login (or "me").
+	LoginArgument ArgumentType = "login"
+	// NumberArgument accepts a whole number.
+	NumberArgument ArgumentType = "number"
+	// ListArgument accepts a comma separated list of values.
+	ListArgument ArgumentType = "list"
+	// LabelsArgument accepts a comma separated list of label names.
+	LabelsArgument ArgumentType = "labels"
+	// LoginsArgument accepts a comma separated list of github logins (or "me").
+	LoginsArgument ArgumentType = "logins"
+	// MilestoneArgument accepts the title of a milestone.
+	MilestoneArgument ArgumentType = "milestone"
+	// TextArgument accepts any value.
+	TextArgument ArgumentType = "text"
+)
+
+// IsList returns true if the argument type accepts a comma separated list of values.
+func (t ArgumentType) IsList() bool {
+	return t == ListArgument || t == LabelsArgument || t == LoginsArgument
+}
+
+// Element is a part of the syntax of a command.
+type Element interface {
+	// match attempts to match the element at the parser's current position, storing any arguments in values. If
+	// the element does not match, the parser's position is left unchanged.
+	match(p *parser, values Values) bool
+	// syntax returns the usage syntax for the element.
+	syntax() string
+	// words returns the keywords used by the element.
+	words() []string
+}
+
+// Keyword returns an element that matches the specified word.
+func Keyword(word string) Element {
+	return &keyword{word: word}
+}
+
+// Flag returns an element that matches the specified word, and records that the flag was supplied.
+func Flag(word string) Element {
+	return &keyword{word: word, flag: true}
+}
+
+// Arg returns an element that matches a single argument of the specified type.
+func Arg(name string, argumentType ArgumentType) Element {
+	return &argument{name: name, argumentType: argumentType}
+}
+
+// Args returns an element that matches one or more arguments of the specified type.
+func Args(name string, argumentType ArgumentType) Element {
+	return &argument{name: name, argumentType: argumentType, variadic: true}
+}
+
+// Choice returns an element that matches any one of the specified words. The word that was matched is stored as
+// the named value.
+func Choice(name string, choices ...string) Element {
+	return &choice{name: name, choices: choices}
+}
+
+// Optional returns an element that matches the supplied elements in order, or nothing at all.
+func Optional(elements ...Element) Element {
+	return &optional{elements: elements}
+}
+
+// Clause returns an optional clause that can be supplied to a command in any order, along with the other clauses
+// in the same set of Clauses.
+func Clause(description string, elements ...Element) *ClauseElement {
+	return &ClauseElement{description: description, elements: elements}
+}
+
+// Clauses returns an element that matches any number of the supplied clauses, in any order.
+func Clauses(clauses ...*ClauseElement) Element {
+	return &clauseSet{clauses: clauses}
+}
+
+// Values are the arguments that were parsed from a command.
+type Values map[string]interface{}
+
+// Has returns true if the named value was supplied.
+func (v Values) Has(name string) bool {
+	_, ok := v[name]
+	return ok
+}
+
+// Int returns the named value as an int, or 0 if it was not supplied. For an issue, the issue number is returned.
+func (v Values) Int(name string) int {
+	switch value := v[name].(type) {
+	case int:
+		return value
+	case IssueRef:
+		return value.Number
+	}
+	return 0
+}
+
+// Issue returns the named value as an issue reference, or an empty reference if it was not supplied.
+func (v Values) Issue(name string) IssueRef {
+	value, _ := v[name].(IssueRef)
+	return value
+}
+
+// String returns the named value as a string, or an empty string if it was not supplied.
+func (v Values) String(name string) string {
+	value, _ := v[name].(string)
+	return value
+}
+
+// Strings returns the named value as a list of strings, or nil if it was not supplied.
+func (v Values) Strings(name string) []string {
+	value, _ := v[name].([]string)
+	return value
+}
+
+// Bool returns true if the named flag was supplied.
+func (v Values) Bool(name string) bool {
+	value, _ := v[name].(bool)
+	return value
+}
+
+func (v Values) copy() Values {
+	c := Values{}
+	for key, value := range v {
+		c[key] = value
+	}
+	return c
+}
+
+func (v Values) restore(snapshot Values) {
+	for key := range v {
+		delete(v, key)
+	}
+	for key, value := range snapshot {
+		v[key] = value
+	}
+}
+
+// expectation records something that the parser expected at a position in the args.
+type expectation struct {
+	position int
+	keyword  string
+	argument *argument
+	end      bool
+}
+
+type parser struct {
+	args         []string
+	pos          int
+	reserved     map[string]bool
+	expectations []expectation
+	// positions records the position at which each named argument was matched.
+	positions map[string]int
+}
+
+func newParser(args []string, reserved map[string]bool) *parser {
+	return &parser{
+		args:      args,
+		reserved:  reserved,
+		positions: map[string]int{},
+	}
+}
+
+func (p *parser) peek() (string, bool) {
+	if p.pos >= len(p.args) {
+		return "", false
+	}
+	return p.args[p.pos], true
+}
+
+func (p *parser) expectKeyword(word string) {
+	p.expectations = append(p.expectations, expectation{position: p.pos, keyword: word})
+}
+
+func (p *parser) expectArgument(a *argument) {
+	p.expectations = append(p.expectations, expectation{position: p.pos, argument: a})
+}
+
+func matchAll(p *parser, values Values, elements []Element) bool {
+	start := p.pos
+	snapshot := values.copy()
+	for _, element := range elements {
+		if !element.match(p, values) {
+			p.pos = start
+			values.restore(snapshot)
+			return false
+		}
+	}
+	return true
+}
+
+func syntaxOf(elements []Element) string {
+	parts := []string{}
+	for _, element := range elements {
+		parts = append(parts, element.syntax())
+	}
+	return strings.Join(parts, " ")
+}
+
+func wordsOf(elements []Element) []string {
+	words := []string{}
+	for _, element := range elements {
+		words = append(words, element.words()...)
+	}
+	return words
+}
+
+type keyword struct {
+	word string
+	flag bool
+}
+
+func (k *keyword) match(p *parser, values Values) bool {
+	symbol, ok := p.peek()
+	if !ok || symbol != k.word {
+		p.expectKeyword(k.word)
+		return false
+	}
+	if k.flag {
+		values[k.word] = true
+	}
+	p.pos++
+	return true
+}
+
+func (k *keyword) syntax() string {
+	return k.word
+}
+
+func (k *keyword) words() []string {
+	return []string{k.word}
+}
+
+type argument struct {
+	name         string
+	argumentType ArgumentType
+	variadic     bool
+	// omittable is true for an issue argument that refers to the current issue when it is omitted (see
+	// Command.OmittableIssue).
+	omittable bool
+}
+
+func (a *argument) match(p *parser, values Values) bool {
+	matched := false
+	for {
+		symbol, ok := p.peek()
+		if !ok || p.reserved[symbol] {
+			p.expectArgument(a)
+			if !matched && a.omittable {
+				values[a.name] = IssueRef{Current: true}
+				p.positions[a.name] = p.pos
+				return true
+			}
+			return matched
+		}
+		value, ok := a.parse(symbol)
+		if !ok {
+			p.expectArgument(a)
+			return matched
+		}
+
+		switch {
+		case a.argumentType.IsList():
+			list, _ := values[a.name].([]string)
+			values[a.name] = append(list, value.([]string)...)
+		case a.variadic:
+			list, _ := values[a.name].([]string)
+			values[a.name] = append(list, symbol)
+		default:
+			values[a.name] = value
+		}
+		if !matched {
+			p.positions[a.name] = p.pos
+		}
+		p.pos++
+		matched = true
+
+		if !a.variadic {
+			return true
+		}
+	}
+}
+
+func (a *argument) parse(symbol string) (interface{}, bool) {
+	switch a.argumentType {
+	case IssueArgument:
+		ref, ok := ParseIssueRef(symbol)
+		return ref, ok
+	case NumberArgument:
+		value, err := strconv.Atoi(symbol)
+		if err != nil || value < 0 {
+			return nil, false
+		}
+		return value, true
+	case ListArgument, LabelsArgument, LoginsArgument:
+		list := []string{}
+		for _, item := range strings.Split(symbol, ",") {
+			item = strings.TrimSpace(item)
+			if item != "" {
+				list = append(list, item)
+			}
+		}
+		return list, true
+	}
+	return symbol, true
+}
+
+func (a *argument) syntax() string {
+	if a.variadic {
+		return "<" + a.name + ">..."
+	}
+	return "<" + a.name + ">"
+}
+
+func (a *argument) words() []string {
+	return nil
+}
+
+type choice struct {
+	name    string
+	choices []string
+}
+
+func (c *choice) match(p *parser, values Values) bool {
+	symbol, ok := p.peek()
+	for _, word := range c.choices {
+		if ok && symbol == word {
+			values[c.name] = word
+			p.pos++
+			return true
+		}
+	}
+	for _, word := range c.choices {
+		p.expectKeyword(word)
+	}
+	return false
+}
+
+func (c *choice) syntax() string {
+	return strings.Join(c.choices, "|")
+}
+
+func (c *choice) words() []string {
+	return c.choices
+}
+
+type optional struct {
+	elements []Element
+}
+
+func (o *optional) match(p *parser, values Values) bool {
+	matchAll(p, values, o.elements)
+	return true
+}
+
+func (o *optional) syntax() string {
+	return "[" + syntaxOf(o.elements) + "]"
+}
+
+func (o *optional) words() []string {
+	return wordsOf(o.elements)
+}
+
+// ClauseElement is a single optional clause within a set of Clauses.
+type ClauseElement struct {
+	description string
+	elements    []Element
+}
+
+type clauseSet struct {
+	clauses []*ClauseElement
+}
+
+func (c *clauseSet) match(p *parser, values Values) bool {
+	for {
+		matched := false
+		for _, clause := range c.clauses {
+			if matchAll(p, values, clause.elements) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return true
+		}
+	}
+}
+
+func (c *clauseSet) syntax() string {
+	return "[parameters]"
+}
+
+func (c *clauseSet) words() []string {
+	words := []string{}
+	for _, clause := range c.clauses {
+		words = append(words, wordsOf(clause.elements)...)
+	}
+	return words
+}
@@ -0,0 +1,118 @@
+package command
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+var (
+	issueNumberPattern = regexp.MustCompile(`^#?([0-9]+)$`)
+	issueRefPattern    = regexp.MustCompile(`^(?:([A-Za-z0-9_.-]+)/)?([A-Za-z0-9_.-]+)#([0-9]+)$`)
+	issueURLPattern    = regexp.MustCompile(`^https?://(?:www\.)?github\.com/([A-Za-z0-9_.-]+)/([A-Za-z0-9_.-]+)/(?:issues|pull)/([0-9]+)(?:[/?#].*)?$`)
+	zenHubURLPattern   = regexp.MustCompile(`^https?://app\.zenhub\.com/workspaces?/[^?#]*/issues/(?:gh/)?([A-Za-z0-9_.-]+)/([A-Za-z0-9_.-]+)/([0-9]+)(?:[/?#].*)?$`)
+)
+
+// IssueRef is a reference to an issue, which may be in a repository other than the current one.
+type IssueRef struct {
+	// Owner is the owner of the repository, or empty if the reference did not include one.
+	Owner string
+	// Repo is the name of the repository, or empty for the current repository.
+	Repo   string
+	Number int
+	// Current is true if the reference is to the issue of the checked out branch, whose number is not yet known.
+	Current bool
+}
+
+// ParseIssueRef parses an issue reference, which is an issue number (i.e. 123 or #123), a number in another
+// repository (i.e. repo#123 or owner/repo#123), the URL of an issue or pull request on github or ZenHub, or "."
+// for the issue of the checked out branch.
+func ParseIssueRef(value string) (IssueRef, bool) {
+	if value == "." {
+		return IssueRef{Current: true}, true
+	}
+	if match := issueNumberPattern.FindStringSubmatch(value); match != nil {
+		return newIssueRef("", "", match[1])
+	}
+	for _, pattern := range []*regexp.Regexp{issueRefPattern, issueURLPattern, zenHubURLPattern} {
+		if match := pattern.FindStringSubmatch(value); match != nil {
+			return newIssueRef(match[1], match[2], match[3])
+		}
+	}
+	return IssueRef{}, false
+}
+
+func newIssueRef(owner, repo, number string) (IssueRef, bool) {
+	value, err := strconv.Atoi(number)
+	if err != nil || value <= 0 {
+		return IssueRef{}, false
+	}
+	return IssueRef{Owner: owner, Repo: repo, Number: value}, true
+}
+
+// String returns the reference in the form owner/repo#123, repo#123 or #123, or "." for the current issue.
+func (r IssueRef) String() string {
+	switch {
+	case r.Current:
+		return "."
+	case r.Repo == "":
+		return fmt.Sprintf("#%v", r.Number)
+	case r.Owner == "":
+		return fmt.Sprintf("%v#%v", r.Repo, r.Number)
+	}
+	return fmt.Sprintf("%v/%v#%v", r.Owner, r.Repo, r.Number)
+}
+
+// CurrentIssuer can optionally be implemented by Actions to supply the issue that the user is working on, which is
+// usually found from the name of the checked out branch. If it is implemented, issue arguments can be "." to refer
+// to that issue. For commands with OmittableIssue set, the first argument can also be left out: it refers to the
+// current issue whenever the next word is not an argument, either because the command ends there or because the
+// word is one of the command's keywords (i.e. "zen move to review").
+type CurrentIssuer interface {
+	CurrentIssue() (int, error)
+}
+
+// resolveCurrentIssues replaces the references to the current issue in the values with its number.
+func (c *Command) resolveCurrentIssues(actions Actions, values Values) error {
+	for name, argumentType := range c.arguments() {
+		if argumentType != IssueArgument || !values.Issue(name).Current {
+			continue
+		}
+		issuer, ok := actions.(CurrentIssuer)
+		if !ok {
+			return fmt.Errorf("the current issue is not supported; supply an issue number")
+		}
+		number, err := issuer.CurrentIssue()
+		if err != nil {
+			return err
+		}
+		values[name] = IssueRef{Number: number}
+	}
+	return nil
+}
+
+// RepoSwitcher can optionally be implemented by Actions to act on repositories other than the current one. If it
+// is implemented, commands whose primary issue argument refers to another repository are run with the actions for
+// that repository (see Command.repository).
+type RepoSwitcher interface {
+	// ForRepo returns the actions for the specified repository. The owner is empty if the issue references did not
+	// include one, in which case the current owner is assumed.
+	ForRepo(owner, repo string) (Actions, error)
+}
+
+// repository returns the repository that the command's primary issue argument refers to, which is the issue
+// argument that the command starts with (i.e. the issue being closed, not the original that it duplicates). The
+// owner and repo are empty if the issue is in the current repository, or if the command has no primary issue.
+// Other issue arguments do not change the repository; the actions resolve them on their own, and plain issue
+// numbers in them refer to the same repository as the primary issue.
+func (c *Command) repository(values Values) (owner, repo string) {
+	if len(c.Syntax) == 0 {
+		return "", ""
+	}
+	argument, ok := c.Syntax[0].(*argument)
+	if !ok || argument.argumentType != IssueArgument || argument.variadic {
+		return "", ""
+	}
+	ref := values.Issue(argument.name)
+	return ref.Owner, ref.Repo
+}
@@ -0,0 +1,130 @@
+package command
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseIssueRef(t *testing.T) {
+	tests := []struct {
+		value string
+		want  IssueRef
+		ok    bool
+	}{
+		{"123", IssueRef{Number: 123}, true},
+		{"#123", IssueRef{Number: 123}, true},
+		{".", IssueRef{Current: true}, true},
+		{"api#7", IssueRef{Repo: "api", Number: 7}, true},
+		{"eltorocorp/api#7", IssueRef{Owner: "eltorocorp", Repo: "api", Number: 7}, true},
+		{"https://github.com/eltorocorp/api/issues/7", IssueRef{Owner: "eltorocorp", Repo: "api", Number: 7}, true},
+		{"https://www.github.com/eltorocorp/api/pull/7/files", IssueRef{Owner: "eltorocorp", Repo: "api", Number: 7}, true},
+		{"https://github.com/eltorocorp/api/issues/7#issuecomment-1", IssueRef{Owner: "eltorocorp", Repo: "api", Number: 7}, true},
+		{"https://app.zenhub.com/workspaces/board-5c1/issues/eltorocorp/api/7", IssueRef{Owner: "eltorocorp", Repo: "api", Number: 7}, true},
+		{"https://app.zenhub.com/workspaces/board-5c1/issues/gh/eltorocorp/api/7", IssueRef{Owner: "eltorocorp", Repo: "api", Number: 7}, true},
+		{"0", IssueRef{}, false},
+		{"#0", IssueRef{}, false},
+		{"-1", IssueRef{}, false},
+		{"12a", IssueRef{}, false},
+		{"api#", IssueRef{}, false},
+		{"a/b/c#1", IssueRef{}, false},
+		{"https://github.com/eltorocorp/api/commit/7", IssueRef{}, false},
+		{"https://example.com/eltorocorp/api/issues/7", IssueRef{}, false},
+		{"99999999999999999999", IssueRef{}, false},
+		{"", IssueRef{}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			got, ok := ParseIssueRef(test.value)
+			if ok != test.ok || got != test.want {
+				t.Errorf("expected %v (%v), got %v (%v)", test.want, test.ok, got, ok)
+			}
+		})
+	}
+}
+
+func TestIssueRefString(t *testing.T) {
+	tests := map[string]IssueRef{
+		"#7":               {Number: 7},
+		"api#7":            {Repo: "api", Number: 7},
+		"eltorocorp/api#7": {Owner: "eltorocorp", Repo: "api", Number: 7},
+		".":                {Current: true},
+	}
+	for want, ref := range tests {
+		if got := ref.String(); got != want {
+			t.Errorf("expected %q, got %q", want, got)
+		}
+	}
+}
+
+func TestOmittedIssue(t *testing.T) {
+	tests := []struct {
+		args    string
+		command string
+		want    IssueRef
+	}{
+		{"show", "show", IssueRef{Current: true}},
+		{"show 12", "show", IssueRef{Number: 12}},
+		{"move to review", "move", IssueRef{Current: true}},
+		{"move 12 to review", "move", IssueRef{Number: 12}},
+		{"label add bug", "label", IssueRef{Current: true}},
+		{"edit title Fixed", "edit", IssueRef{Current: true}},
+		{"close .", "close", IssueRef{Current: true}},
+	}
+	for _, test := range tests {
+		t.Run(test.args, func(t *testing.T) {
+			command, values, _, err := parse(strings.Fields(test.args))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if command.Name() != test.command {
+				t.Errorf("expected the %q command, got %q", test.command, command.Name())
+			}
+			if got := values.Issue("issue"); got != test.want {
+				t.Errorf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+
+	for _, args := range []string{"close", "close as not_planned", "drop", "start", "milestone 2.0"} {
+		t.Run(args, func(t *testing.T) {
+			if _, _, _, err := parse(strings.Fields(args)); err == nil {
+				t.Errorf("expected the issue to be required")
+			}
+		})
+	}
+}
+
+func TestRepository(t *testing.T) {
+	tests := []struct {
+		args        string
+		owner, repo string
+	}{
+		{"close 123", "", ""},
+		{"close api#123", "", "api"},
+		{"close eltorocorp/api#123 as duplicate of 99", "eltorocorp", "api"},
+		{"close 123 as duplicate of api#99", "", ""},
+		{"close 123 as duplicate of eltorocorp/api#99", "", ""},
+		{"create Title in epic other#5", "", ""},
+		{"move api#12 to review", "", "api"},
+	}
+	for _, test := range tests {
+		t.Run(test.args, func(t *testing.T) {
+			command, values, _, err := parse(strings.Fields(test.args))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			owner, repo := command.repository(values)
+			if owner != test.owner || repo != test.repo {
+				t.Errorf("expected %q/%q, got %q/%q", test.owner, test.repo, owner, repo)
+			}
+		})
+	}
+
+	_, values, _, err := parse(strings.Fields("close 123 as duplicate of api#99"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := values.Issue("original"), (IssueRef{Repo: "api", Number: 99}); got != want {
+		t.Errorf("expected the original to keep its repository (%v), got %v", want, got)
+	}
+}
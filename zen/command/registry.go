@@ -0,0 +1,169 @@
+package command
+
+import (
+	"sort"
+	"strings"
+)
+
+const (
+	usageIndent       = 4
+	usageClauseIndent = 8
+	usageColumn       = 37
+)
+
+// Command describes the syntax of a command and the action that it runs.
+type Command struct {
+	// Keywords are the words that identify the command (i.e. "pick", "up").
+	Keywords []string
+	// Syntax describes the arguments and clauses that follow the keywords.
+	Syntax []Element
+	// Summary is a one line description of the command.
+	Summary string
+	// Details are any further lines of description, separated by newlines.
+	Details string
+	// OmittableIssue is true if the issue that is the first argument can be left out to refer to the current issue
+	// (see CurrentIssuer). It is only set for commands where acting on the current issue by accident does no harm.
+	OmittableIssue bool
+	// Examples are examples of how to use the command.
+	Examples []Example
+	// Hidden commands can be run, but are not included in the usage information or in completions.
+	Hidden bool
+	// Run runs the command with the parsed values.
+	Run func(actions Actions, values Values) error
+}
+
+var registry = []*Command{}
+
+// Register adds a command to the set of commands that can be parsed.
+func Register(command *Command) {
+	if command.OmittableIssue && len(command.Syntax) > 0 {
+		if argument, ok := command.Syntax[0].(*argument); ok && argument.argumentType == IssueArgument && !argument.variadic {
+			argument.omittable = true
+		}
+	}
+	registry = append(registry, command)
+}
+
+// Name returns the keywords of the command joined by spaces.
+func (c *Command) Name() string {
+	return strings.Join(c.Keywords, " ")
+}
+
+// Usage returns the usage syntax for the command.
+func (c *Command) Usage() string {
+	return strings.TrimSpace(c.Name() + " " + syntaxOf(c.Syntax))
+}
+
+func (c *Command) elements() []Element {
+	elements := []Element{}
+	for _, word := range c.Keywords {
+		elements = append(elements, Keyword(word))
+	}
+	return append(elements, c.Syntax...)
+}
+
+// reserved returns the words that cannot be used as arguments to the command, since they are keywords within it.
+func (c *Command) reserved() map[string]bool {
+	reserved := map[string]bool{}
+	for _, word := range wordsOf(c.elements()) {
+		reserved[word] = true
+	}
+	return reserved
+}
+
+// commands returns the registered commands in the order in which they should be tried when parsing. Commands with
+// more keywords are tried first, so that (for example) "milestone create" is preferred over "milestone".
+func commands() []*Command {
+	sorted := append([]*Command{}, registry...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return len(sorted[i].Keywords) > len(sorted[j].Keywords)
+	})
+	return sorted
+}
+
+// parse finds the command that matches all of the supplied args. The positions at which each argument was
+// matched are returned along with the values. If no command matches, a ParseError is returned describing the
+// furthest that any command got.
+func parse(args []string) (*Command, Values, map[string]int, error) {
+	expectations := []expectation{}
+	topic := ""
+	furthest := 0
+	for _, command := range commands() {
+		p := newParser(args, command.reserved())
+		values := Values{}
+		if matchAll(p, values, command.elements()) {
+			if p.pos == len(args) {
+				return command, values, p.positions, nil
+			}
+			p.expectations = append(p.expectations, expectation{position: p.pos, end: true})
+		}
+		for _, e := range p.expectations {
+			if e.position > furthest {
+				furthest = e.position
+				topic = command.Keywords[0]
+			}
+		}
+		expectations = append(expectations, p.expectations...)
+	}
+	parseError := newParseError(args, expectations)
+	parseError.Topic = topic
+	return nil, nil, nil, parseError
+}
+
+// arguments returns the name and type of each argument in the command.
+func (c *Command) arguments() map[string]ArgumentType {
+	arguments := map[string]ArgumentType{}
+	var collect func(elements []Element)
+	collect = func(elements []Element) {
+		for _, element := range elements {
+			switch e := element.(type) {
+			case *argument:
+				arguments[e.name] = e.argumentType
+			case *optional:
+				collect(e.elements)
+			case *clauseSet:
+				for _, clause := range e.clauses {
+					collect(clause.elements)
+				}
+			}
+		}
+	}
+	collect(c.Syntax)
+	return arguments
+}
+
+// Candidate is a possible next word for a partially typed command.
+type Candidate struct {
+	// Keyword is set if the next word can be a keyword.
+	Keyword string
+	// ArgumentName and ArgumentType are set if the next word can be an argument.
+	ArgumentName string
+	ArgumentType ArgumentType
+}
+
+// Complete returns the candidates for the word following the supplied args.
+func Complete(args []string) []Candidate {
+	candidates := []Candidate{}
+	seen := map[Candidate]bool{}
+	for _, command := range commands() {
+		if command.Hidden {
+			continue
+		}
+		p := newParser(args, command.reserved())
+		matchAll(p, Values{}, command.elements())
+		for _, e := range p.expectations {
+			if e.position != len(args) {
+				continue
+			}
+			candidate := Candidate{Keyword: e.keyword}
+			if e.argument != nil {
+				candidate = Candidate{ArgumentName: e.argument.name, ArgumentType: e.argument.argumentType}
+			}
+			if !seen[candidate] {
+				seen[candidate] = true
+				candidates = append(candidates, candidate)
+			}
+		}
+	}
+	return candidates
+}
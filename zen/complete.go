@@ -0,0 +1,70 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/eltorocorp/zencli/zen/command"
+	"github.com/eltorocorp/zencli/zen/completion"
+	"github.com/eltorocorp/zencli/zen/github"
+)
+
+// completionValues returns the values that an argument of the specified type can be completed with. The values
+// are fetched through the cache, so completion does not wait on github or ZenHub for long.
+func (a *Actions) completionValues(cache *completion.Cache, argumentType command.ArgumentType) []completion.Value {
+	switch argumentType {
+	case command.PipelineArgument:
+		return cache.Get("pipelines", func() ([]completion.Value, error) {
+			names, err := a.PipelineNames()
+			values := []completion.Value{}
+			for _, name := range names {
+				values = append(values, completion.Value{Text: name})
+			}
+			return values, err
+		})
+	case command.IssueArgument:
+		return cache.Get("issues", func() ([]completion.Value, error) {
+			issues, err := a.githubAPI.GetIssuesForRepo()
+			if err != nil {
+				return nil, err
+			}
+			values := []completion.Value{}
+			for _, issue := range issues {
+				values = append(values, completion.Value{Text: strconv.Itoa(issue.Number), Description: issue.Title})
+			}
+			return values, nil
+		})
+	case command.LoginArgument, command.LoginsArgument:
+		logins := cache.Get("logins", func() ([]completion.Value, error) {
+			users, err := a.githubAPI.GetAssignees()
+			values := []completion.Value{}
+			for _, user := range users {
+				values = append(values, completion.Value{Text: user.Login})
+			}
+			return values, err
+		})
+		return append([]completion.Value{{Text: "me", Description: "the authenticated user"}}, logins...)
+	case command.LabelsArgument:
+		return cache.Get("labels", func() ([]completion.Value, error) {
+			labels, err := a.githubAPI.GetLabels()
+			values := []completion.Value{}
+			for _, label := range labels {
+				values = append(values, completion.Value{Text: label.Name, Description: label.Description})
+			}
+			return values, err
+		})
+	case command.MilestoneArgument:
+		return cache.Get("milestones", func() ([]completion.Value, error) {
+			milestones, err := a.githubAPI.GetMilestones(github.StateOpen)
+			values := []completion.Value{}
+			for _, milestone := range milestones {
+				description := ""
+				if milestone.DueOn != nil {
+					description = "due " + milestone.DueOn.Format(dateFormat)
+				}
+				values = append(values, completion.Value{Text: milestone.Title, Description: description})
+			}
+			return values, err
+		})
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package completion
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultTTL is how long cached values are used before they are fetched again.
+	DefaultTTL = 5 * time.Minute
+	// DefaultTimeout is how long a fetch can take before the stale cached values are used instead.
+	DefaultTimeout = 1500 * time.Millisecond
+	// DefaultColdTimeout is how long a fetch can take when there are no cached values at all. It is longer than the
+	// timeout so that the cache can be filled the first time, even on a slow connection.
+	DefaultColdTimeout = 4 * time.Second
+)
+
+// Value is a possible completion, with an optional description (i.e. the title of an issue).
+type Value struct {
+	Text        string `json:"text"`
+	Description string `json:"description,omitempty"`
+}
+
+// Cache stores the values for each kind of completion in a directory, so that they can be reused across
+// invocations of zen.
+type Cache struct {
+	dir         string
+	ttl         time.Duration
+	timeout     time.Duration
+	coldTimeout time.Duration
+}
+
+type cacheEntry struct {
+	FetchedAt time.Time `json:"fetched_at"`
+	Values    []Value   `json:"values"`
+}
+
+// NewCache returns a reference to a cache that stores values in the specified directory.
+func NewCache(dir string) *Cache {
+	return &Cache{
+		dir:         dir,
+		ttl:         DefaultTTL,
+		timeout:     DefaultTimeout,
+		coldTimeout: DefaultColdTimeout,
+	}
+}
+
+// DefaultDir returns the directory in which completions are cached for the specified repository (i.e.
+// eltorocorp/zencli).
+func DefaultDir(repository string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "zen", "completion", filepath.FromSlash(repository)), nil
+}
+
+// Get returns the cached values for the key. If the values are missing or stale, they are fetched again; if the
+// fetch fails or takes longer than the timeout, the stale values (or no values) are returned instead.
+func (c *Cache) Get(key string, fetch func() ([]Value, error)) []Value {
+	entry, err := c.load(key)
+	if err == nil && time.Since(entry.FetchedAt) < c.ttl {
+		return entry.Values
+	}
+	timeout := c.timeout
+	if err != nil {
+		timeout = c.coldTimeout
+	}
+
+	type result struct {
+		values []Value
+		err    error
+	}
+	results := make(chan result, 1)
+	go func() {
+		values, err := fetch()
+		results <- result{values, err}
+	}()
+
+	select {
+	case r := <-results:
+		if r.err != nil {
+			return entry.Values
+		}
+		c.store(key, &cacheEntry{FetchedAt: time.Now(), Values: r.values})
+		return r.values
+	case <-time.After(timeout):
+		return entry.Values
+	}
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+func (c *Cache) load(key string) (*cacheEntry, error) {
+	entry := &cacheEntry{}
+	content, err := ioutil.ReadFile(c.path(key))
+	if err != nil {
+		return entry, err
+	}
+	err = json.Unmarshal(content, entry)
+	return entry, err
+}
+
+// store writes the entry to the cache. Failures are ignored, since the values are only a convenience.
+func (c *Cache) store(key string, entry *cacheEntry) {
+	content, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if os.MkdirAll(c.dir, 0700) != nil {
+		return
+	}
+	ioutil.WriteFile(c.path(key), content, 0600)
+}
+
+// Filter returns the values that start with the supplied prefix. If list is true, the prefix is treated as a comma
+// separated list, and only the last item in the list is completed (i.e. "bug,fr" completes to "bug,frontend").
+func Filter(values []Value, prefix string, list bool) []Value {
+	head := ""
+	if list {
+		if i := strings.LastIndex(prefix, ","); i >= 0 {
+			head, prefix = prefix[:i+1], prefix[i+1:]
+		}
+	}
+	filtered := []Value{}
+	for _, value := range values {
+		if strings.HasPrefix(strings.ToLower(value.Text), strings.ToLower(prefix)) {
+			filtered = append(filtered, Value{Text: head + value.Text, Description: value.Description})
+		}
+	}
+	return filtered
+}
+
+// Write writes the values one per line, with the description (if any) separated from the value by a tab, which is
+// the format that the completion scripts expect.
+func Write(w io.Writer, values []Value) {
+	for _, value := range values {
+		if value.Description == "" {
+			fmt.Fprintln(w, value.Text)
+			continue
+		}
+		fmt.Fprintf(w, "%v\t%v\n", value.Text, value.Description)
+	}
+}
+
+// Unquote removes the shell quoting from a word as it was typed (i.e. "\"in progress\"" or "in\ progress"). Bash and
+// zsh pass words to the completion function exactly as they were typed, including any unterminated quote.
+func Unquote(word string) string {
+	b := &strings.Builder{}
+	quote := rune(0)
+	escaped := false
+	for _, r := range word {
+		switch {
+		case escaped:
+			b.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+		case quote == 0 && (r == '"' || r == '\''):
+			quote = r
+		case r == quote:
+			quote = 0
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
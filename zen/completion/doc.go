@@ -0,0 +1,2 @@
+// Package completion provides the shell completion scripts for zen, and a cache for the dynamic values that they complete, so that completing a word never blocks the shell for long.
+package completion
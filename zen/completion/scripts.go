@@ -0,0 +1,76 @@
+package completion
+
+import "fmt"
+
+// Script returns the completion script for the specified shell. Each script calls `zen __complete` with the words
+// before the cursor followed by the word being completed, and reads back one completion per line.
+func Script(shell string) (string, error) {
+	switch shell {
+	case "bash":
+		return bashScript, nil
+	case "zsh":
+		return zshScript, nil
+	case "fish":
+		return fishScript, nil
+	}
+	return "", fmt.Errorf("completion is not available for %v; the supported shells are bash, zsh and fish", shell)
+}
+
+const bashScript = `# bash completion for zen. To enable it, add this to your ~/.bashrc:
+#
+#     source <(zen completion bash)
+#
+_zen() {
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+    local IFS=$'\n'
+    local candidate
+    COMPREPLY=()
+    for candidate in $(zen __complete "${COMP_WORDS[@]:1:COMP_CWORD-1}" "$cur" 2>/dev/null); do
+        COMPREPLY+=("$(printf '%q' "${candidate%%$'\t'*}")")
+    done
+}
+complete -F _zen zen
+`
+
+const zshScript = `#compdef zen
+# zsh completion for zen. To enable it, add this to your ~/.zshrc:
+#
+#     source <(zen completion zsh)
+#
+# or save it as _zen in a directory in your $fpath.
+_zen() {
+    local -a candidates
+    local line value description
+    for line in "${(@f)$(zen __complete "${(@)words[2,CURRENT-1]}" "${words[CURRENT]}" 2>/dev/null)}"; do
+        [[ -z $line ]] && continue
+        value=${line%%$'\t'*}
+        value=${value//:/\\:}
+        if [[ $line == *$'\t'* ]]; then
+            description=${line#*$'\t'}
+            candidates+=("$value:$description")
+        else
+            candidates+=("$value")
+        fi
+    done
+    _describe -t values 'zen' candidates
+}
+
+if [[ $funcstack[1] == _zen ]]; then
+    _zen "$@"
+else
+    compdef _zen zen
+fi
+`
+
+const fishScript = `# fish completion for zen. To enable it, run:
+#
+#     zen completion fish > ~/.config/fish/completions/zen.fish
+#
+function __zen_complete
+    set -l words (commandline -opc)
+    set -e words[1]
+    zen __complete $words (commandline -ct) 2>/dev/null
+end
+
+complete -c zen -f -a '(__zen_complete)'
+`
@@ -0,0 +1,250 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PathVariable is the environment variable that overrides the location of the configuration file.
+const PathVariable = "ZENCLI_CONFIG"
+
+var (
+	sectionPattern  = regexp.MustCompile(`^\[\s*([A-Za-z0-9_.-]+)\s*\]\s*(#.*)?$`)
+	keyValuePattern = regexp.MustCompile(`^("(?:[^"\\]|\\.)*"|[A-Za-z0-9_-]+)\s*=\s*(.*)$`)
+	bareKeyPattern  = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+)
+
+// File is a configuration file.
+type File struct {
+	path  string
+	lines []string
+}
+
+// DefaultPath returns the location of the configuration file, which is $ZENCLI_CONFIG if it is set, and otherwise
+// zen/config.toml in the user's configuration directory (i.e. ~/.config/zen/config.toml).
+func DefaultPath() (string, error) {
+	if path := os.Getenv(PathVariable); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "zen", "config.toml"), nil
+}
+
+// Load reads the configuration file at the specified path. A missing file is treated as an empty file.
+func Load(path string) (*File, error) {
+	file := &File{path: path}
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return file, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	text := strings.TrimRight(strings.Replace(string(content), "\r\n", "\n", -1), "\n")
+	if text != "" {
+		file.lines = strings.Split(text, "\n")
+	}
+	for i, line := range file.lines {
+		_, _, _, ok := parseLine(line)
+		if !ok {
+			return nil, fmt.Errorf("%v:%v: unable to parse '%v'", path, i+1, strings.TrimSpace(line))
+		}
+	}
+	return file, nil
+}
+
+// Path returns the location of the file.
+func (f *File) Path() string {
+	return f.path
+}
+
+// Get returns the value of the key in the specified section, and whether the key was found.
+func (f *File) Get(section, key string) (string, bool) {
+	i := f.find(section, key)
+	if i < 0 {
+		return "", false
+	}
+	_, _, value, _ := parseLine(f.lines[i])
+	return value, true
+}
+
+// Section returns all of the keys and values in the specified section.
+func (f *File) Section(section string) map[string]string {
+	values := map[string]string{}
+	current := ""
+	for _, line := range f.lines {
+		name, key, value, _ := parseLine(line)
+		switch {
+		case name != "":
+			current = name
+		case key != "" && current == section:
+			values[key] = value
+		}
+	}
+	return values
+}
+
+// Keys returns the keys in the specified section, sorted.
+func (f *File) Keys(section string) []string {
+	keys := []string{}
+	for key := range f.Section(section) {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Set sets the value of the key in the specified section. An existing key is updated where it is; otherwise the
+// key is added to the end of the section, and the section is added to the end of the file if it does not exist.
+func (f *File) Set(section, key, value string) {
+	line := formatKey(key) + " = " + strconv.Quote(value)
+	if i := f.find(section, key); i >= 0 {
+		f.lines[i] = line
+		return
+	}
+
+	start, end := f.sectionBounds(section)
+	if start < 0 {
+		if len(f.lines) > 0 {
+			f.lines = append(f.lines, "")
+		}
+		f.lines = append(f.lines, "["+section+"]", line)
+		return
+	}
+	// Insert after the last non-blank line of the section, so the blank line before the next section is kept.
+	for end > start+1 && strings.TrimSpace(f.lines[end-1]) == "" {
+		end--
+	}
+	f.lines = append(f.lines[:end], append([]string{line}, f.lines[end:]...)...)
+}
+
+// Delete removes the key from the specified section, and returns false if the key was not found.
+func (f *File) Delete(section, key string) bool {
+	i := f.find(section, key)
+	if i < 0 {
+		return false
+	}
+	f.lines = append(f.lines[:i], f.lines[i+1:]...)
+	return true
+}
+
+// Save writes the file, creating its directory if necessary.
+func (f *File) Save() error {
+	err := os.MkdirAll(filepath.Dir(f.path), 0755)
+	if err != nil {
+		return err
+	}
+	content := strings.Join(f.lines, "\n")
+	if content != "" {
+		content += "\n"
+	}
+	return ioutil.WriteFile(f.path, []byte(content), 0644)
+}
+
+// find returns the index of the line that sets the key in the specified section, or -1.
+func (f *File) find(section, key string) int {
+	current := ""
+	for i, line := range f.lines {
+		name, lineKey, _, _ := parseLine(line)
+		switch {
+		case name != "":
+			current = name
+		case lineKey == key && current == section:
+			return i
+		}
+	}
+	return -1
+}
+
+// sectionBounds returns the index of the section's header line, and the index of the line after the end of the
+// section. start is -1 if the section does not exist.
+func (f *File) sectionBounds(section string) (start, end int) {
+	start = -1
+	for i, line := range f.lines {
+		name, _, _, _ := parseLine(line)
+		if name == "" {
+			continue
+		}
+		if start >= 0 {
+			return start, i
+		}
+		if name == section {
+			start = i
+		}
+	}
+	return start, len(f.lines)
+}
+
+// parseLine parses a single line of the file. Blank lines and comments return no section or key. ok is false if
+// the line cannot be parsed.
+func parseLine(line string) (section, key, value string, ok bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", "", "", true
+	}
+	if match := sectionPattern.FindStringSubmatch(line); match != nil {
+		return match[1], "", "", true
+	}
+	match := keyValuePattern.FindStringSubmatch(line)
+	if match == nil {
+		return "", "", "", false
+	}
+	key = match[1]
+	if strings.HasPrefix(key, `"`) {
+		key, _ = strconv.Unquote(key)
+	}
+	value, ok = parseValue(match[2])
+	return "", key, value, ok
+}
+
+// parseValue parses a basic ("...") or literal ('...') string, or a bare value such as a number or boolean, which
+// is returned as it was written. A trailing comment is ignored.
+func parseValue(text string) (string, bool) {
+	switch {
+	case strings.HasPrefix(text, `"`):
+		for i := 1; i < len(text); i++ {
+			if text[i] == '\\' {
+				i++
+				continue
+			}
+			if text[i] == '"' {
+				value, err := strconv.Unquote(text[:i+1])
+				return value, err == nil && isComment(text[i+1:])
+			}
+		}
+		return "", false
+	case strings.HasPrefix(text, "'"):
+		end := strings.Index(text[1:], "'")
+		if end < 0 {
+			return "", false
+		}
+		return text[1 : end+1], isComment(text[end+2:])
+	}
+	if i := strings.Index(text, "#"); i >= 0 {
+		text = text[:i]
+	}
+	text = strings.TrimSpace(text)
+	return text, text != ""
+}
+
+func isComment(text string) bool {
+	text = strings.TrimSpace(text)
+	return text == "" || strings.HasPrefix(text, "#")
+}
+
+func formatKey(key string) string {
+	if bareKeyPattern.MatchString(key) {
+		return key
+	}
+	return strconv.Quote(key)
+}
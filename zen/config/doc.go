@@ -0,0 +1,2 @@
+// Package config reads and writes zen's configuration file, which is a small subset of TOML: [sections] containing key = "value" pairs, and comments. Values are edited in place, so comments and layout in the file are preserved.
+package config
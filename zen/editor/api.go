@@ -0,0 +1,169 @@
+package editor
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// Scissors marks the end of a message. The scissors line and everything below it are ignored (as with git's
+// scissors cleanup mode), which allows markdown headings above it to be kept.
+const Scissors = "# ------------------------ >8 ------------------------"
+
+// ErrEmptyMessage is returned when the composed message is empty.
+var ErrEmptyMessage = errors.New("aborting due to empty message")
+
+// MetadataKeys are the metadata fields that can be supplied in the comments of a composed issue.
+var MetadataKeys = []string{"pipeline", "labels", "estimate", "assignees"}
+
+var metadataPattern = regexp.MustCompile(`^#\s*([a-z]+):\s*(.*)$`)
+
+// Message represents an issue that was composed in the editor.
+type Message struct {
+	Title    string
+	Body     string
+	Metadata map[string]string
+}
+
+// Edit writes the supplied template to a temporary file, opens the user's editor on that file, and returns the
+// contents of the file once the editor exits.
+//
+// The editor is taken from $VISUAL or $EDITOR (in that order), and defaults to vi.
+func Edit(template string) (string, error) {
+	file, err := ioutil.TempFile("", "zen-*.md")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.WriteString(template)
+	if err != nil {
+		file.Close()
+		return "", err
+	}
+	err = file.Close()
+	if err != nil {
+		return "", err
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	// The editor is run through the shell (as git does) so that editors with arguments, such as "code --wait", work.
+	cmd := exec.Command("sh", "-c", editor+` "$@"`, editor, file.Name())
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err = cmd.Run()
+	if err != nil {
+		return "", err
+	}
+
+	contents, err := ioutil.ReadFile(file.Name())
+	return string(contents), err
+}
+
+// ComposeIssue opens the user's editor on the supplied template and parses the result with ParseIssue.
+func ComposeIssue(template string) (*Message, error) {
+	text, err := Edit(template)
+	if err != nil {
+		return nil, err
+	}
+	return ParseIssue(text)
+}
+
+// ComposeText opens the user's editor on the supplied template and returns the result with any comments removed.
+func ComposeText(template string) (string, error) {
+	text, err := Edit(template)
+	if err != nil {
+		return "", err
+	}
+	text = Clean(text)
+	if text == "" {
+		return "", ErrEmptyMessage
+	}
+	return text, nil
+}
+
+// ParseIssue parses an issue that was composed in the editor.
+//
+// The first line of the message is the title, and the remaining lines are the body. Any metadata fields
+// (see MetadataKeys) below the scissors line that have a value are returned in the Metadata of the message.
+func ParseIssue(text string) (*Message, error) {
+	message := &Message{
+		Metadata: map[string]string{},
+	}
+	comments := ""
+	if index := strings.Index(text, Scissors); index >= 0 {
+		comments = text[index:]
+	}
+	for _, line := range strings.Split(comments, "\n") {
+		match := metadataPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil || !isMetadataKey(match[1]) {
+			continue
+		}
+		value := strings.TrimSpace(match[2])
+		if value != "" {
+			message.Metadata[match[1]] = value
+		}
+	}
+
+	text = Clean(text)
+	if text == "" {
+		return nil, ErrEmptyMessage
+	}
+
+	lines := strings.SplitN(text, "\n", 2)
+	message.Title = strings.TrimSpace(lines[0])
+	if len(lines) > 1 {
+		message.Body = strings.TrimSpace(lines[1])
+	}
+	return message, nil
+}
+
+// Clean cleans up a message the way git does for commit messages. If the message contains the scissors line, it
+// and everything below it are removed, otherwise lines starting with '#' are removed. Trailing whitespace is
+// stripped from each line, consecutive blank lines are collapsed, and leading and trailing blank lines are removed.
+func Clean(text string) string {
+	scissors := false
+	if index := strings.Index(text, Scissors); index >= 0 {
+		text = text[:index]
+		scissors = true
+	}
+
+	cleaned := []string{}
+	blank := false
+	for _, line := range strings.Split(text, "\n") {
+		if !scissors && strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" {
+			blank = len(cleaned) > 0
+			continue
+		}
+		if blank {
+			cleaned = append(cleaned, "")
+			blank = false
+		}
+		cleaned = append(cleaned, line)
+	}
+	return strings.Join(cleaned, "\n")
+}
+
+func isMetadataKey(key string) bool {
+	for _, metadataKey := range MetadataKeys {
+		if key == metadataKey {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,2 @@
+// Package editor opens the user's editor to compose messages, and parses the composed messages the way git parses commit messages.
+package editor
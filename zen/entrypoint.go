@@ -17,6 +17,8 @@ func main() {
 
 	githubAPI := github.New(githubAuthToken, repoName, repoOwner)
 	zenHubAPI := zenhub.New(zenHubAuthToken, githubAPI)
+	zenHubAPI.SetGraphQLKey(os.Getenv("ZENCLI_ZENHUBGRAPHQLTOKEN"))
+	zenHubAPI.SetWorkspace(os.Getenv("ZENCLI_WORKSPACE"))
 	actions := NewActions(githubAPI, zenHubAPI)
 
 	cmd := command.New(os.Args, actions)
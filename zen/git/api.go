@@ -0,0 +1,250 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// DefaultBranchTemplate is the template used to name a branch for an issue when none is configured.
+const DefaultBranchTemplate = "{{.Number}}-{{slug .Title}}"
+
+// DefaultBranchPattern matches the issue number at the start of a branch name, or after a prefix such as
+// "feature/", so it finds the issue in the branches named by DefaultBranchTemplate.
+const DefaultBranchPattern = `^(?:[^/]+/)*#?([0-9]+)(?:[-_.]|$)`
+
+// maxSlugLength limits the length of a slug, so branch names stay readable.
+const maxSlugLength = 50
+
+var slugSeparatorPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// githubRemotePattern matches the remote url of a github repository, capturing its full name.
+var githubRemotePattern = regexp.MustCompile(`(?i)^(?:(?:https?|ssh|git)://(?:[^@/]+@)?|[^@/]+@)?(?:www\.)?github\.com[:/]([\w.-]+/[\w.-]+?)(?:\.git)?/?$`)
+
+// Repo is a local git repository.
+type Repo struct {
+	// Dir is the top level directory of the working tree.
+	Dir    string
+	dryRun io.Writer
+}
+
+// BranchData is the data that a branch template is executed with.
+type BranchData struct {
+	Number int
+	Title  string
+	// Repo is the name of the github repository that the issue belongs to.
+	Repo string
+}
+
+// Open returns the repository that contains the specified directory (or the current directory, if dir is empty).
+func Open(dir string) (*Repo, error) {
+	repo := &Repo{Dir: dir}
+	top, err := repo.git("rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("unable to find a git repository: %v", err)
+	}
+	repo.Dir = top
+	return repo, nil
+}
+
+// SetDryRun causes commands that would change the repository to be written to w instead of being run. A nil
+// writer runs them again.
+func (r *Repo) SetDryRun(w io.Writer) {
+	r.dryRun = w
+}
+
+// IsDirty returns true if the working tree has uncommitted changes, including untracked files.
+func (r *Repo) IsDirty() (bool, error) {
+	status, err := r.git("status", "--porcelain")
+	return status != "", err
+}
+
+// BranchExists returns true if there is a local branch with the specified name.
+func (r *Repo) BranchExists(name string) bool {
+	_, err := r.git("rev-parse", "--verify", "--quiet", "refs/heads/"+name)
+	return err == nil
+}
+
+// Checkout checks out the specified branch, creating it from the current HEAD if it does not exist. It returns
+// true if the branch was created.
+func (r *Repo) Checkout(name string) (bool, error) {
+	args := []string{"checkout", name}
+	created := !r.BranchExists(name)
+	if created {
+		args = []string{"checkout", "-b", name}
+	}
+	if r.dryRun != nil {
+		fmt.Fprintf(r.dryRun, "git %v\n", strings.Join(args, " "))
+		return created, nil
+	}
+	_, err := r.git(args...)
+	return created, err
+}
+
+// HooksDir returns the directory that git runs the repository's hooks from, which respects core.hooksPath.
+func (r *Repo) HooksDir() (string, error) {
+	dir, err := r.git("rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(r.Dir, dir)
+	}
+	return dir, nil
+}
+
+// CommentChar returns the character that starts a comment line in commit messages, from core.commentChar. It is
+// "#" by default, and may be "auto", in which case git picks a character that no line of the message starts with.
+func (r *Repo) CommentChar() (string, error) {
+	return r.git("config", "--default", "#", "--get", "core.commentChar")
+}
+
+// RemoteRepo returns the full name ("owner/repo") of the github repository that the specified remote points to. An
+// empty string is returned if the remote does not exist or is not on github.
+func (r *Repo) RemoteRepo(remote string) string {
+	url, err := r.git("config", "--get", "remote."+remote+".url")
+	if err != nil {
+		return ""
+	}
+	return GitHubRepo(url)
+}
+
+// GitHubRepo returns the full name ("owner/repo") of the github repository in a remote url, which can be an https
+// url ("https://github.com/owner/repo.git"), an scp-like ssh address ("git@github.com:owner/repo.git") or an ssh url
+// ("ssh://git@github.com/owner/repo"). An empty string is returned if the url is not for a github repository.
+func GitHubRepo(url string) string {
+	match := githubRemotePattern.FindStringSubmatch(strings.TrimSpace(url))
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+// BranchName executes the branch template with the specified data, and checks that the result is a valid branch
+// name. The template can use the slug function, which turns text into lowercase words separated by hyphens.
+func BranchName(branchTemplate string, data BranchData) (string, error) {
+	t, err := template.New("branch").Funcs(template.FuncMap{"slug": Slug}).Parse(branchTemplate)
+	if err != nil {
+		return "", fmt.Errorf("unable to parse the branch template: %v", err)
+	}
+	b := &bytes.Buffer{}
+	err = t.Execute(b, data)
+	if err != nil {
+		return "", fmt.Errorf("unable to execute the branch template: %v", err)
+	}
+	name := strings.TrimSpace(b.String())
+	_, err = exec.Command("git", "check-ref-format", "--branch", name).Output()
+	if err != nil {
+		return "", fmt.Errorf("'%v' is not a valid branch name; check the branch template", name)
+	}
+	return name, nil
+}
+
+// CurrentBranch returns the name of the branch that is checked out in the repository that contains the specified
+// directory (or the current directory, if dir is empty). HEAD is read from the .git directory, so git does not need
+// to be run.
+func CurrentBranch(dir string) (string, error) {
+	gitDir, err := findGitDir(dir)
+	if err != nil {
+		return "", err
+	}
+	head, err := ioutil.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return "", err
+	}
+	ref := strings.TrimSpace(string(head))
+	if !strings.HasPrefix(ref, "ref: refs/heads/") {
+		return "", fmt.Errorf("HEAD is detached, so no branch is checked out")
+	}
+	return strings.TrimPrefix(ref, "ref: refs/heads/"), nil
+}
+
+// IssueFromBranch returns the issue number in the branch name, which is the first group captured by the pattern.
+func IssueFromBranch(branch, pattern string) (int, error) {
+	expression, err := regexp.Compile(pattern)
+	if err != nil {
+		return 0, fmt.Errorf("unable to parse the branch pattern: %v", err)
+	}
+	if expression.NumSubexp() < 1 {
+		return 0, fmt.Errorf("the branch pattern '%v' must capture the issue number in a group", pattern)
+	}
+	match := expression.FindStringSubmatch(branch)
+	if match == nil {
+		return 0, fmt.Errorf("the branch '%v' does not name an issue; supply an issue number", branch)
+	}
+	number, err := strconv.Atoi(strings.TrimPrefix(match[1], "#"))
+	if err != nil || number <= 0 {
+		return 0, fmt.Errorf("the branch pattern matched '%v' in '%v', which is not an issue number", match[1], branch)
+	}
+	return number, nil
+}
+
+// findGitDir finds the .git directory of the repository that contains dir, by looking in dir and its parents. In
+// a worktree or submodule, .git is a file that names the directory.
+func findGitDir(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		path := filepath.Join(dir, ".git")
+		info, err := os.Stat(path)
+		if err == nil && info.IsDir() {
+			return path, nil
+		}
+		if err == nil {
+			content, err := ioutil.ReadFile(path)
+			if err != nil {
+				return "", err
+			}
+			gitDir := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(string(content)), "gitdir:"))
+			if !filepath.IsAbs(gitDir) {
+				gitDir = filepath.Join(dir, gitDir)
+			}
+			return gitDir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("unable to find a git repository")
+		}
+		dir = parent
+	}
+}
+
+// Slug turns text into lowercase words separated by hyphens, i.e. "Fix the login page!" becomes
+// "fix-the-login-page". Long slugs are cut at a word boundary.
+func Slug(text string) string {
+	slug := strings.Trim(slugSeparatorPattern.ReplaceAllString(strings.ToLower(text), "-"), "-")
+	if len(slug) <= maxSlugLength {
+		return slug
+	}
+	slug = slug[:maxSlugLength]
+	if i := strings.LastIndex(slug, "-"); i > 0 {
+		slug = slug[:i]
+	}
+	return slug
+}
+
+// git runs a git command in the repository, and returns its output without the trailing newline.
+func (r *Repo) git(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.Dir
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("git %v: %v", args[0], message)
+		}
+		return "", fmt.Errorf("git %v: %v", args[0], err)
+	}
+	return strings.TrimRight(string(output), "\n"), nil
+}
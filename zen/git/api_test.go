@@ -0,0 +1,24 @@
+package git
+
+import "testing"
+
+func TestGitHubRepo(t *testing.T) {
+	tests := map[string]string{
+		"https://github.com/eltorocorp/zencli.git":       "eltorocorp/zencli",
+		"https://github.com/eltorocorp/zencli":           "eltorocorp/zencli",
+		"https://user@github.com/eltorocorp/zencli.git/": "eltorocorp/zencli",
+		"git@github.com:eltorocorp/zencli.git":           "eltorocorp/zencli",
+		"ssh://git@github.com/eltorocorp/zen.cli.git":    "eltorocorp/zen.cli",
+		"git://github.com/eltorocorp/zencli":             "eltorocorp/zencli",
+		"https://gitlab.com/eltorocorp/zencli.git":       "",
+		"https://github.com/eltorocorp":                  "",
+		"https://github.com/eltorocorp/zencli/tree/main": "",
+		"/home/user/zencli":                              "",
+		"":                                               "",
+	}
+	for url, want := range tests {
+		if got := GitHubRepo(url); got != want {
+			t.Errorf("%q: expected %q, got %q", url, want, got)
+		}
+	}
+}
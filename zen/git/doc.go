@@ -0,0 +1,2 @@
+// Package git works with the local checkout of a repository by running the git command, so that zen can create and inspect the branches that issues are worked on in. Nothing is ever pushed or fetched.
+package git
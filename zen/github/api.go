@@ -2,22 +2,55 @@ package github
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
 )
 
 const (
 	githubRoot           = "https://api.github.com"
 	githubV3AcceptHeader = "application/vnd.github.v3+json"
+	pageSize             = 100
 )
 
+// StatusError is returned when an endpoint responds with an unexpected status code.
+type StatusError struct {
+	Endpoint   string
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("the %v endpoint returned %v", e.Endpoint, e.StatusCode)
+}
+
 // API provides methods for interacting with github.
 type API struct {
 	githubAuthToken string
 	RepoName        string
 	ownerName       string
+
+	// mutex guards the values that are memoized for the lifetime of the API, since they do not change.
+	mutex      sync.Mutex
+	repository *Repository
+	account    *sharedAccount
+
+	// dryRun receives a description of each request that would change data, instead of it being sent, if it is
+	// not nil.
+	dryRun io.Writer
+}
+
+// sharedAccount holds the authenticated user, which is shared by every API created with the same token.
+type sharedAccount struct {
+	mutex sync.Mutex
+	user  *User
 }
 
 // New returns a reference to a github API.
@@ -26,96 +59,101 @@ func New(githubAuthToken, repoName, ownerName string) *API {
 		githubAuthToken: githubAuthToken,
 		RepoName:        repoName,
 		ownerName:       ownerName,
+		account:         &sharedAccount{},
 	}
 }
 
-//GetRepoID returns the ID for the target repository
-func (a *API) GetRepoID() (*int, error) {
-	client := http.DefaultClient
-	getRepoURI := fmt.Sprintf("%v/repos/%v/%v?access_token=%v", githubRoot, a.ownerName, a.RepoName, a.githubAuthToken)
-	request, err := createDefaultRequest(http.MethodGet, getRepoURI)
-	if err != nil {
-		return nil, err
-	}
-
-	response, err := client.Do(request)
-	if err != nil {
-		return nil, err
-	}
-
-	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("the repo endpoint returned %v", response.StatusCode)
+// WithRepo returns a reference to a github API for a different repository, using the same credentials.
+func (a *API) WithRepo(ownerName, repoName string) *API {
+	return &API{
+		githubAuthToken: a.githubAuthToken,
+		RepoName:        repoName,
+		ownerName:       ownerName,
+		account:         a.account,
+		dryRun:          a.dryRun,
 	}
+}
 
-	body, err := ioutil.ReadAll(response.Body)
-	if err != nil {
-		return nil, err
-	}
+// SetDryRun makes the API write a description of each request that would change data to w, instead of sending
+// it. Requests that only read data are still sent. Passing nil turns the dry run off.
+func (a *API) SetDryRun(w io.Writer) {
+	a.dryRun = w
+}
 
-	repository := new(Repository)
-	err = json.Unmarshal(body, repository)
+// Owner returns the owner of the target repository (i.e. eltorocorp).
+func (a *API) Owner() string {
+	return a.ownerName
+}
 
-	return &repository.ID, err
+// FullName returns the owner and name of the target repository (i.e. eltorocorp/zencli).
+func (a *API) FullName() string {
+	return a.ownerName + "/" + a.RepoName
 }
 
-// GetIssuesForRepo gets a list of issues for the target repository.
-func (a *API) GetIssuesForRepo() (*[]*Issue, error) {
-	client := http.DefaultClient
-	getRepoURI := fmt.Sprintf("%v/repos/%v/%v/issues?access_token=%v", githubRoot, a.ownerName, a.RepoName, a.githubAuthToken)
-	request, err := createDefaultRequest(http.MethodGet, getRepoURI)
-	if err != nil {
-		return nil, err
+// GetRepo returns the target repository. The repository is only fetched once.
+func (a *API) GetRepo() (*Repository, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if a.repository != nil {
+		return a.repository, nil
 	}
 
-	response, err := client.Do(request)
+	getRepoURI := fmt.Sprintf("%v/repos/%v/%v?access_token=%v", githubRoot, a.ownerName, a.RepoName, a.githubAuthToken)
+	repository := new(Repository)
+	err := a.doRequest(http.MethodGet, getRepoURI, nil, repository, http.StatusOK, "repo")
 	if err != nil {
 		return nil, err
 	}
+	a.repository = repository
+	return repository, nil
+}
 
-	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("the issues endpoint returned %v", response.StatusCode)
-	}
-
-	body, err := ioutil.ReadAll(response.Body)
+// GetRepoID returns the ID for the target repository. The ID is only fetched once.
+func (a *API) GetRepoID() (*int, error) {
+	repository, err := a.GetRepo()
 	if err != nil {
 		return nil, err
 	}
+	return &repository.ID, nil
+}
 
-	issues := new([]*Issue)
-	err = json.Unmarshal(body, issues)
+// GetRepoByID returns the repository with the specified ID, which may be any repository the user can access.
+func (a *API) GetRepoByID(id int) (*Repository, error) {
+	getRepoURI := fmt.Sprintf("%v/repositories/%v?access_token=%v", githubRoot, id, a.githubAuthToken)
+	repository := new(Repository)
+	err := a.doRequest(http.MethodGet, getRepoURI, nil, repository, http.StatusOK, "repository")
+	return repository, err
+}
 
+// GetIssuesForRepo returns all of the open issues (and pull requests) for the target repository.
+func (a *API) GetIssuesForRepo() ([]*Issue, error) {
+	getIssuesURI := fmt.Sprintf("%v/repos/%v/%v/issues?per_page=%v&access_token=%v", githubRoot, a.ownerName, a.RepoName, pageSize, a.githubAuthToken)
+	issues := []*Issue{}
+	err := a.doPagedRequest(getIssuesURI, "issues", func(body []byte) error {
+		page := []*Issue{}
+		err := json.Unmarshal(body, &page)
+		issues = append(issues, page...)
+		return err
+	})
 	return issues, err
 }
 
-// GetAuthenticatedUser gets the current authenticated user.
+// GetAuthenticatedUser gets the current authenticated user. The user is only fetched once.
 func (a *API) GetAuthenticatedUser() (*User, error) {
-	client := http.DefaultClient
-	getRepoURI := fmt.Sprintf("%v/user?access_token=%v", githubRoot, a.githubAuthToken)
-	request, err := createDefaultRequest(http.MethodGet, getRepoURI)
-
-	if err != nil {
-		return nil, err
+	a.account.mutex.Lock()
+	defer a.account.mutex.Unlock()
+	if a.account.user != nil {
+		return a.account.user, nil
 	}
 
-	response, err := client.Do(request)
-	if err != nil {
-		return nil, err
-	}
-
-	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("the issues endpoint returned %v", response.StatusCode)
-	}
-
-	body, err := ioutil.ReadAll(response.Body)
+	getUserURI := fmt.Sprintf("%v/user?access_token=%v", githubRoot, a.githubAuthToken)
+	user := new(User)
+	err := a.doRequest(http.MethodGet, getUserURI, nil, user, http.StatusOK, "authenticated user")
 	if err != nil {
 		return nil, err
 	}
-
-	user := new(User)
-	err = json.Unmarshal(body, user)
-
-	return user, err
-
+	a.account.user = user
+	return user, nil
 }
 
 // RemoveAuthenticatedUserFromIssue removes the current authenticated user from the specified issue.
@@ -128,29 +166,8 @@ func (a *API) RemoveAuthenticatedUserFromIssue(issue int) error {
 	assigneeToRemove := &Assignees{
 		List: []string{currentUser.Login},
 	}
-	assigneesJSON, err := json.Marshal(assigneeToRemove)
-	if err != nil {
-		return err
-	}
-
-	client := http.DefaultClient
-	getRepoURI := fmt.Sprintf("%v/repos/%v/%v/issues/%v/assignees?access_token=%v", githubRoot, a.ownerName, a.RepoName, issue, a.githubAuthToken)
-	request, err := createDefaultRequest(http.MethodDelete, getRepoURI)
-	if err != nil {
-		return err
-	}
-
-	request.Body = ioutil.NopCloser(bytes.NewReader(assigneesJSON))
-	response, err := client.Do(request)
-	if err != nil {
-		return err
-	}
-
-	if response.StatusCode != http.StatusOK {
-		return fmt.Errorf("the remove assignee endpoint returned %v", response.StatusCode)
-	}
-
-	return nil
+	removeAssigneeURI := fmt.Sprintf("%v/repos/%v/%v/issues/%v/assignees?access_token=%v", githubRoot, a.ownerName, a.RepoName, issue, a.githubAuthToken)
+	return a.doRequest(http.MethodDelete, removeAssigneeURI, assigneeToRemove, nil, http.StatusOK, "remove assignee")
 }
 
 // AssignAuthenticatedUserToIssue assigns the current authenticated user to the specified issue.
@@ -163,134 +180,501 @@ func (a *API) AssignAuthenticatedUserToIssue(issue int) error {
 	assigneeToAdd := &Assignees{
 		List: []string{currentUser.Login},
 	}
-	assigneesJSON, err := json.Marshal(assigneeToAdd)
-	if err != nil {
-		return err
-	}
+	addAssigneeURI := fmt.Sprintf("%v/repos/%v/%v/issues/%v/assignees?access_token=%v", githubRoot, a.ownerName, a.RepoName, issue, a.githubAuthToken)
+	return a.doRequest(http.MethodPost, addAssigneeURI, assigneeToAdd, nil, http.StatusCreated, "add assignee")
+}
 
-	client := http.DefaultClient
-	getRepoURI := fmt.Sprintf("%v/repos/%v/%v/issues/%v/assignees?access_token=%v", githubRoot, a.ownerName, a.RepoName, issue, a.githubAuthToken)
-	request, err := createDefaultRequest(http.MethodPost, getRepoURI)
+// DryRunNumber is the number that CreateIssue and CreatePullRequest give the new issue or pull request during a
+// dry run, when nothing is created. Requests for it that are described afterwards use it in place of a real number.
+const DryRunNumber = -1
+
+// CreateIssue creates a new issue and returns the issue number for the new issue (DryRunNumber during a dry run).
+func (a *API) CreateIssue(issueToCreate *NewIssue) (int, error) {
+	createIssueURI := fmt.Sprintf("%v/repos/%v/%v/issues?access_token=%v", githubRoot, a.ownerName, a.RepoName, a.githubAuthToken)
+	newIssue := new(Issue)
+	err := a.doRequest(http.MethodPost, createIssueURI, issueToCreate, newIssue, http.StatusCreated, "create issue")
 	if err != nil {
-		return err
+		return 0, err
+	}
+	if a.dryRun != nil {
+		return DryRunNumber, nil
 	}
+	return newIssue.Number, nil
+}
 
-	request.Body = ioutil.NopCloser(bytes.NewReader(assigneesJSON))
-	response, err := client.Do(request)
-	if err != nil {
-		return err
+// CreateComment adds a comment with the specified body to the specified issue.
+func (a *API) CreateComment(issue int, body string) error {
+	createCommentURI := fmt.Sprintf("%v/repos/%v/%v/issues/%v/comments?access_token=%v", githubRoot, a.ownerName, a.RepoName, issue, a.githubAuthToken)
+	comment := struct {
+		Body string `json:"body"`
+	}{
+		Body: body,
 	}
+	return a.doRequest(http.MethodPost, createCommentURI, &comment, nil, http.StatusCreated, "create comment")
+}
 
-	if response.StatusCode != http.StatusCreated {
-		return fmt.Errorf("the add assignee endpoint returned %v", response.StatusCode)
+// GetComments returns the comments on the specified issue, oldest first. If since is non-zero, only comments
+// that were updated at or after that time are returned.
+func (a *API) GetComments(issue int, since time.Time) ([]*Comment, error) {
+	getCommentsURI := fmt.Sprintf("%v/repos/%v/%v/issues/%v/comments?per_page=%v&access_token=%v", githubRoot, a.ownerName, a.RepoName, issue, pageSize, a.githubAuthToken)
+	if !since.IsZero() {
+		getCommentsURI += "&since=" + url.QueryEscape(since.UTC().Format(time.RFC3339))
 	}
 
-	return nil
+	comments := []*Comment{}
+	err := a.doPagedRequest(getCommentsURI, "comments", func(body []byte) error {
+		page := []*Comment{}
+		err := json.Unmarshal(body, &page)
+		comments = append(comments, page...)
+		return err
+	})
+	return comments, err
 }
 
-// CreateIssue creates a new issue and returns the issue number for the new issue.
-func (a *API) CreateIssue(title string) (int, error) {
-	client := http.DefaultClient
-	getRepoURI := fmt.Sprintf("%v/repos/%v/%v/issues?access_token=%v", githubRoot, a.ownerName, a.RepoName, a.githubAuthToken)
-	request, err := createDefaultRequest(http.MethodPost, getRepoURI)
+// GetDirectoryContents returns the entries in the specified directory of the target repository. If the directory
+// does not exist, this method returns an empty list.
+func (a *API) GetDirectoryContents(path string) ([]*Content, error) {
+	getContentsURI := fmt.Sprintf("%v/repos/%v/%v/contents/%v?access_token=%v", githubRoot, a.ownerName, a.RepoName, path, a.githubAuthToken)
+	contents := []*Content{}
+	err := a.doRequest(http.MethodGet, getContentsURI, nil, &contents, http.StatusOK, "contents")
+	if statusErr, ok := err.(*StatusError); ok && statusErr.StatusCode == http.StatusNotFound {
+		return []*Content{}, nil
+	}
+	return contents, err
+}
+
+// GetFileContents returns the decoded contents of the specified file in the target repository.
+func (a *API) GetFileContents(path string) ([]byte, error) {
+	getContentsURI := fmt.Sprintf("%v/repos/%v/%v/contents/%v?access_token=%v", githubRoot, a.ownerName, a.RepoName, path, a.githubAuthToken)
+	content := new(Content)
+	err := a.doRequest(http.MethodGet, getContentsURI, nil, content, http.StatusOK, "contents")
 	if err != nil {
-		return 0, err
+		return nil, err
+	}
+	if content.Encoding != "base64" {
+		return nil, fmt.Errorf("the contents of %v have an unsupported encoding (%v)", path, content.Encoding)
 	}
+	return base64.StdEncoding.DecodeString(content.Content)
+}
+
+// GetLabels returns the labels defined for the target repository.
+func (a *API) GetLabels() ([]*Label, error) {
+	getLabelsURI := fmt.Sprintf("%v/repos/%v/%v/labels?per_page=%v&access_token=%v", githubRoot, a.ownerName, a.RepoName, pageSize, a.githubAuthToken)
+	labels := []*Label{}
+	err := a.doPagedRequest(getLabelsURI, "labels", func(body []byte) error {
+		page := []*Label{}
+		err := json.Unmarshal(body, &page)
+		labels = append(labels, page...)
+		return err
+	})
+	return labels, err
+}
+
+// GetAssignees returns the users that issues in the target repository can be assigned to.
+func (a *API) GetAssignees() ([]*User, error) {
+	getAssigneesURI := fmt.Sprintf("%v/repos/%v/%v/assignees?per_page=%v&access_token=%v", githubRoot, a.ownerName, a.RepoName, pageSize, a.githubAuthToken)
+	users := []*User{}
+	err := a.doPagedRequest(getAssigneesURI, "assignees", func(body []byte) error {
+		page := []*User{}
+		err := json.Unmarshal(body, &page)
+		users = append(users, page...)
+		return err
+	})
+	return users, err
+}
+
+// CreateLabel creates the supplied label for the target repository.
+func (a *API) CreateLabel(label *Label) error {
+	createLabelURI := fmt.Sprintf("%v/repos/%v/%v/labels?access_token=%v", githubRoot, a.ownerName, a.RepoName, a.githubAuthToken)
+	return a.doRequest(http.MethodPost, createLabelURI, label, nil, http.StatusCreated, "create label")
+}
 
-	issueToCreate := struct {
-		Title string `json:"title"`
+// UpdateLabel replaces the label with the specified name with the supplied label.
+func (a *API) UpdateLabel(name string, label *Label) error {
+	updateLabelURI := fmt.Sprintf("%v/repos/%v/%v/labels/%v?access_token=%v", githubRoot, a.ownerName, a.RepoName, url.PathEscape(name), a.githubAuthToken)
+	labelUpdate := struct {
+		NewName     string `json:"new_name"`
+		Color       string `json:"color"`
+		Description string `json:"description"`
 	}{
-		Title: title,
-	}
-	issueToCreateJSON, err := json.Marshal(&issueToCreate)
-	if err != nil {
-		return 0, err
+		NewName:     label.Name,
+		Color:       label.Color,
+		Description: label.Description,
 	}
-	request.Body = ioutil.NopCloser(bytes.NewReader(issueToCreateJSON))
-	response, err := client.Do(request)
+	return a.doRequest(http.MethodPatch, updateLabelURI, &labelUpdate, nil, http.StatusOK, "update label")
+}
+
+// DeleteLabel deletes the label with the specified name from the target repository.
+func (a *API) DeleteLabel(name string) error {
+	deleteLabelURI := fmt.Sprintf("%v/repos/%v/%v/labels/%v?access_token=%v", githubRoot, a.ownerName, a.RepoName, url.PathEscape(name), a.githubAuthToken)
+	return a.doRequest(http.MethodDelete, deleteLabelURI, nil, nil, http.StatusNoContent, "delete label")
+}
+
+// AddLabelsToIssue adds the specified labels to the specified issue.
+func (a *API) AddLabelsToIssue(issue int, labels []string) error {
+	addLabelsURI := fmt.Sprintf("%v/repos/%v/%v/issues/%v/labels?access_token=%v", githubRoot, a.ownerName, a.RepoName, issue, a.githubAuthToken)
+	return a.doRequest(http.MethodPost, addLabelsURI, &IssueLabels{List: labels}, nil, http.StatusOK, "add labels")
+}
+
+// RemoveLabelFromIssue removes the specified label from the specified issue.
+func (a *API) RemoveLabelFromIssue(issue int, label string) error {
+	removeLabelURI := fmt.Sprintf("%v/repos/%v/%v/issues/%v/labels/%v?access_token=%v", githubRoot, a.ownerName, a.RepoName, issue, url.PathEscape(label), a.githubAuthToken)
+	return a.doRequest(http.MethodDelete, removeLabelURI, nil, nil, http.StatusOK, "remove label")
+}
+
+// SetIssueLabels replaces all of the labels on the specified issue with the specified labels.
+func (a *API) SetIssueLabels(issue int, labels []string) error {
+	setLabelsURI := fmt.Sprintf("%v/repos/%v/%v/issues/%v/labels?access_token=%v", githubRoot, a.ownerName, a.RepoName, issue, a.githubAuthToken)
+	return a.doRequest(http.MethodPut, setLabelsURI, &IssueLabels{List: labels}, nil, http.StatusOK, "set labels")
+}
+
+// GetMilestones returns the milestones for the target repository with the specified state ("open", "closed" or
+// "all"), ordered by due date.
+func (a *API) GetMilestones(state string) ([]*Milestone, error) {
+	getMilestonesURI := fmt.Sprintf("%v/repos/%v/%v/milestones?state=%v&sort=due_on&per_page=%v&access_token=%v", githubRoot, a.ownerName, a.RepoName, state, pageSize, a.githubAuthToken)
+	milestones := []*Milestone{}
+	err := a.doPagedRequest(getMilestonesURI, "milestones", func(body []byte) error {
+		page := []*Milestone{}
+		err := json.Unmarshal(body, &page)
+		milestones = append(milestones, page...)
+		return err
+	})
+	return milestones, err
+}
+
+// GetMilestone returns the milestone with the specified title (ignoring case). Open milestones are preferred over
+// closed milestones with the same title. If the specified milestone does not exist for the current repository,
+// this method will return nil and an error.
+func (a *API) GetMilestone(title string) (*Milestone, error) {
+	milestone, err := a.FindMilestone(title)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-
-	if response.StatusCode != http.StatusCreated {
-		return 0, fmt.Errorf("the create issue endpoint returned %v", response.StatusCode)
+	if milestone == nil {
+		return nil, fmt.Errorf("milestone '%v' does not exist for this repository", title)
 	}
+	return milestone, nil
+}
 
-	body, err := ioutil.ReadAll(response.Body)
+// FindMilestone returns the milestone with the specified title (ignoring case), preferring an open milestone if
+// several have the title, or nil if there is no such milestone.
+func (a *API) FindMilestone(title string) (*Milestone, error) {
+	milestones, err := a.GetMilestones("all")
 	if err != nil {
-		return 0, err
+		return nil, err
+	}
+	var match *Milestone
+	for _, milestone := range milestones {
+		if strings.ToLower(milestone.Title) == strings.ToLower(title) && (match == nil || milestone.State == StateOpen) {
+			match = milestone
+		}
 	}
+	return match, nil
+}
 
-	newIssue := new(Issue)
-	err = json.Unmarshal(body, newIssue)
+// GetMilestoneNumber returns the number of the milestone with the specified title. If the specified milestone
+// does not exist for the current repository, this method will return 0 and an error.
+func (a *API) GetMilestoneNumber(title string) (int, error) {
+	milestone, err := a.GetMilestone(title)
 	if err != nil {
 		return 0, err
 	}
-	return newIssue.Number, nil
+	return milestone.Number, nil
 }
 
-// CloseIssue closes the specified issue.
-func (a *API) CloseIssue(issue int) error {
-	issueToClose := struct {
-		State string `json:"state"`
+// CreateMilestone creates a milestone with the specified title and returns the new milestone. If dueOn is
+// non-nil it is used as the due date of the milestone.
+func (a *API) CreateMilestone(title string, dueOn *time.Time) (*Milestone, error) {
+	createMilestoneURI := fmt.Sprintf("%v/repos/%v/%v/milestones?access_token=%v", githubRoot, a.ownerName, a.RepoName, a.githubAuthToken)
+	milestoneToCreate := struct {
+		Title string     `json:"title"`
+		DueOn *time.Time `json:"due_on,omitempty"`
 	}{
-		State: "closed",
+		Title: title,
+		DueOn: dueOn,
 	}
-	issueToCloseJSON, err := json.Marshal(&issueToClose)
-	if err != nil {
+	milestone := new(Milestone)
+	err := a.doRequest(http.MethodPost, createMilestoneURI, &milestoneToCreate, milestone, http.StatusCreated, "create milestone")
+	return milestone, err
+}
+
+// GetIssuesForMilestone returns all of the issues (open and closed) in the specified milestone.
+func (a *API) GetIssuesForMilestone(milestone int) ([]*Issue, error) {
+	getIssuesURI := fmt.Sprintf("%v/repos/%v/%v/issues?milestone=%v&state=all&per_page=%v&access_token=%v", githubRoot, a.ownerName, a.RepoName, milestone, pageSize, a.githubAuthToken)
+	issues := []*Issue{}
+	err := a.doPagedRequest(getIssuesURI, "issues", func(body []byte) error {
+		page := []*Issue{}
+		err := json.Unmarshal(body, &page)
+		issues = append(issues, page...)
+		return err
+	})
+	return issues, err
+}
+
+// GetPullRequest returns the specified pull request.
+func (a *API) GetPullRequest(number int) (*PullRequest, error) {
+	getPullRequestURI := fmt.Sprintf("%v/repos/%v/%v/pulls/%v?access_token=%v", githubRoot, a.ownerName, a.RepoName, number, a.githubAuthToken)
+	result := new(PullRequest)
+	err := a.doRequest(http.MethodGet, getPullRequestURI, nil, result, http.StatusOK, "pull request")
+	return result, err
+}
+
+// CreatePullRequest opens a pull request, and returns it (numbered DryRunNumber during a dry run).
+func (a *API) CreatePullRequest(pullRequest *NewPullRequest) (*PullRequest, error) {
+	createPullRequestURI := fmt.Sprintf("%v/repos/%v/%v/pulls?access_token=%v", githubRoot, a.ownerName, a.RepoName, a.githubAuthToken)
+	result := new(PullRequest)
+	err := a.doRequest(http.MethodPost, createPullRequestURI, pullRequest, result, http.StatusCreated, "create pull request")
+	if err == nil && a.dryRun != nil {
+		result.Number = DryRunNumber
+	}
+	return result, err
+}
+
+// RequestReviewers requests reviews of the specified pull request from users and teams.
+func (a *API) RequestReviewers(number int, reviewers *ReviewRequest) error {
+	requestReviewersURI := fmt.Sprintf("%v/repos/%v/%v/pulls/%v/requested_reviewers?access_token=%v", githubRoot, a.ownerName, a.RepoName, number, a.githubAuthToken)
+	return a.doRequest(http.MethodPost, requestReviewersURI, reviewers, nil, http.StatusCreated, "request reviewers")
+}
+
+// GetReviews returns the reviews of the specified pull request, oldest first.
+func (a *API) GetReviews(number int) ([]*Review, error) {
+	getReviewsURI := fmt.Sprintf("%v/repos/%v/%v/pulls/%v/reviews?per_page=%v&access_token=%v", githubRoot, a.ownerName, a.RepoName, number, pageSize, a.githubAuthToken)
+	reviews := []*Review{}
+	err := a.doPagedRequest(getReviewsURI, "reviews", func(body []byte) error {
+		page := []*Review{}
+		err := json.Unmarshal(body, &page)
+		reviews = append(reviews, page...)
+		return err
+	})
+	return reviews, err
+}
+
+// GetCombinedStatus returns the combined commit status of the specified ref.
+func (a *API) GetCombinedStatus(ref string) (*CombinedStatus, error) {
+	getStatusURI := fmt.Sprintf("%v/repos/%v/%v/commits/%v/status?access_token=%v", githubRoot, a.ownerName, a.RepoName, url.PathEscape(ref), a.githubAuthToken)
+	result := new(CombinedStatus)
+	err := a.doRequest(http.MethodGet, getStatusURI, nil, result, http.StatusOK, "status")
+	return result, err
+}
+
+// GetCheckRuns returns the check runs for the specified ref.
+func (a *API) GetCheckRuns(ref string) (*CheckRuns, error) {
+	getCheckRunsURI := fmt.Sprintf("%v/repos/%v/%v/commits/%v/check-runs?per_page=%v&access_token=%v", githubRoot, a.ownerName, a.RepoName, url.PathEscape(ref), pageSize, a.githubAuthToken)
+	result := new(CheckRuns)
+	err := a.doRequest(http.MethodGet, getCheckRunsURI, nil, result, http.StatusOK, "check runs")
+	return result, err
+}
+
+// GetTimeline returns the events in the timeline of the specified issue, oldest first.
+func (a *API) GetTimeline(issue int) ([]*TimelineEvent, error) {
+	getTimelineURI := fmt.Sprintf("%v/repos/%v/%v/issues/%v/timeline?per_page=%v&access_token=%v", githubRoot, a.ownerName, a.RepoName, issue, pageSize, a.githubAuthToken)
+	events := []*TimelineEvent{}
+	err := a.doPagedRequest(getTimelineURI, "timeline", func(body []byte) error {
+		page := []*TimelineEvent{}
+		err := json.Unmarshal(body, &page)
+		events = append(events, page...)
 		return err
+	})
+	return events, err
+}
+
+// GetIssueEvents returns the events (i.e. closed or assigned) on all of the issues and pull requests in the
+// repository since the specified time, newest first.
+func (a *API) GetIssueEvents(since time.Time) ([]*IssueEvent, error) {
+	getEventsURI := fmt.Sprintf("%v/repos/%v/%v/issues/events?per_page=%v&access_token=%v", githubRoot, a.ownerName, a.RepoName, pageSize, a.githubAuthToken)
+	events := []*IssueEvent{}
+	err := a.doPagedRequest(getEventsURI, "issue events", func(body []byte) error {
+		page := []*IssueEvent{}
+		err := json.Unmarshal(body, &page)
+		if err != nil {
+			return err
+		}
+		for _, event := range page {
+			if event.CreatedAt.Before(since) {
+				return errStopPaging
+			}
+			events = append(events, event)
+		}
+		return nil
+	})
+	return events, err
+}
+
+// GetRepoComments returns the comments on all of the issues and pull requests in the repository that were updated
+// since the specified time.
+func (a *API) GetRepoComments(since time.Time) ([]*Comment, error) {
+	getCommentsURI := fmt.Sprintf("%v/repos/%v/%v/issues/comments?per_page=%v&since=%v&access_token=%v", githubRoot, a.ownerName, a.RepoName, pageSize, url.QueryEscape(since.UTC().Format(time.RFC3339)), a.githubAuthToken)
+	comments := []*Comment{}
+	err := a.doPagedRequest(getCommentsURI, "comments", func(body []byte) error {
+		page := []*Comment{}
+		err := json.Unmarshal(body, &page)
+		comments = append(comments, page...)
+		return err
+	})
+	return comments, err
+}
+
+// SearchIssues returns the issues and pull requests in the repository that match the search query (i.e.
+// "is:open assignee:octocat"). The repository qualifier is added to the query.
+func (a *API) SearchIssues(query string) ([]*Issue, error) {
+	query = fmt.Sprintf("repo:%v %v", a.FullName(), query)
+	searchURI := fmt.Sprintf("%v/search/issues?q=%v&per_page=%v&access_token=%v", githubRoot, url.QueryEscape(query), pageSize, a.githubAuthToken)
+	issues := []*Issue{}
+	err := a.doPagedRequest(searchURI, "search", func(body []byte) error {
+		page := &struct {
+			Items []*Issue `json:"items"`
+		}{}
+		err := json.Unmarshal(body, page)
+		issues = append(issues, page.Items...)
+		return err
+	})
+	return issues, err
+}
+
+// GetUser returns the user with the specified login.
+func (a *API) GetUser(login string) (*User, error) {
+	getUserURI := fmt.Sprintf("%v/users/%v?access_token=%v", githubRoot, url.PathEscape(login), a.githubAuthToken)
+	user := new(User)
+	err := a.doRequest(http.MethodGet, getUserURI, nil, user, http.StatusOK, "user")
+	return user, err
+}
+
+// GetIssue returns the specified issue.
+func (a *API) GetIssue(issue int) (*Issue, error) {
+	getIssueURI := fmt.Sprintf("%v/repos/%v/%v/issues/%v?access_token=%v", githubRoot, a.ownerName, a.RepoName, issue, a.githubAuthToken)
+	result := new(Issue)
+	err := a.doRequest(http.MethodGet, getIssueURI, nil, result, http.StatusOK, "issue")
+	return result, err
+}
+
+// UpdateIssue applies the supplied patch to the specified issue. Only the fields that are set on the patch are
+// changed.
+func (a *API) UpdateIssue(issue int, patch *IssuePatch) error {
+	updateIssueURI := fmt.Sprintf("%v/repos/%v/%v/issues/%v?access_token=%v", githubRoot, a.ownerName, a.RepoName, issue, a.githubAuthToken)
+	return a.doRequest(http.MethodPatch, updateIssueURI, patch, nil, http.StatusOK, "update issue")
+}
+
+// doRequest sends a request to the specified uri and verifies that the response has the expected status code.
+// If payload is non-nil it is sent as the JSON body of the request, and if out is non-nil the response body is
+// decoded into it. The endpoint name is only used to describe the endpoint in any error that is returned.
+func (a *API) doRequest(method, uri string, payload, out interface{}, expectedStatus int, endpoint string) error {
+	if a.dryRun != nil && method != http.MethodGet {
+		return describeRequest(a.dryRun, method, uri, payload)
 	}
 
 	client := http.DefaultClient
-	getRepoURI := fmt.Sprintf("%v/repos/%v/%v/issues/%v?access_token=%v", githubRoot, a.ownerName, a.RepoName, issue, a.githubAuthToken)
-	request, err := createDefaultRequest(http.MethodPatch, getRepoURI)
+	request, err := createDefaultRequest(method, uri)
 	if err != nil {
 		return err
 	}
 
-	request.Body = ioutil.NopCloser(bytes.NewReader(issueToCloseJSON))
+	if payload != nil {
+		payloadJSON, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		request.Header.Add("Content-Type", "application/json")
+		request.Body = ioutil.NopCloser(bytes.NewReader(payloadJSON))
+	}
+
 	response, err := client.Do(request)
 	if err != nil {
 		return err
 	}
+	defer response.Body.Close()
 
-	if response.StatusCode != http.StatusOK {
-		return fmt.Errorf("the close issue endpoint returned %v", response.StatusCode)
+	if response.StatusCode != expectedStatus {
+		return &StatusError{Endpoint: endpoint, StatusCode: response.StatusCode}
 	}
 
-	return nil
-}
-
-// OpenIssue opens the specified issue.
-func (a *API) OpenIssue(issue int) error {
-	issueToClose := struct {
-		State string `json:"state"`
-	}{
-		State: "open",
+	if out == nil {
+		return nil
 	}
-	issueToCloseJSON, err := json.Marshal(&issueToClose)
+
+	body, err := ioutil.ReadAll(response.Body)
 	if err != nil {
 		return err
 	}
+	return json.Unmarshal(body, out)
+}
+
+// errStopPaging can be returned by the appendPage function passed to doPagedRequest to stop fetching pages, i.e.
+// once the results are older than needed.
+var errStopPaging = errors.New("stop paging")
 
+// doPagedRequest sends a GET request to the specified uri, and to each subsequent page of results identified by the
+// Link header of the response. The body of each page is passed to appendPage.
+func (a *API) doPagedRequest(uri, endpoint string, appendPage func(body []byte) error) error {
 	client := http.DefaultClient
-	getRepoURI := fmt.Sprintf("%v/repos/%v/%v/issues/%v?access_token=%v", githubRoot, a.ownerName, a.RepoName, issue, a.githubAuthToken)
-	request, err := createDefaultRequest(http.MethodPatch, getRepoURI)
-	if err != nil {
-		return err
+	for uri != "" {
+		request, err := createDefaultRequest(http.MethodGet, uri)
+		if err != nil {
+			return err
+		}
+
+		response, err := client.Do(request)
+		if err != nil {
+			return err
+		}
+
+		body, err := ioutil.ReadAll(response.Body)
+		response.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		if response.StatusCode != http.StatusOK {
+			return &StatusError{Endpoint: endpoint, StatusCode: response.StatusCode}
+		}
+
+		err = appendPage(body)
+		if err == errStopPaging {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		uri = nextPageURI(response.Header.Get("Link"))
 	}
+	return nil
+}
 
-	request.Body = ioutil.NopCloser(bytes.NewReader(issueToCloseJSON))
-	response, err := client.Do(request)
+// nextPageURI returns the uri of the next page from a Link header, or an empty string if there is no next page.
+func nextPageURI(linkHeader string) string {
+	for _, link := range strings.Split(linkHeader, ",") {
+		parts := strings.Split(link, ";")
+		if len(parts) < 2 {
+			continue
+		}
+		for _, param := range parts[1:] {
+			if strings.TrimSpace(param) == `rel="next"` {
+				return strings.Trim(strings.TrimSpace(parts[0]), "<>")
+			}
+		}
+	}
+	return ""
+}
+
+// describeRequest writes the method, path and payload of a request to w, without the access token.
+func describeRequest(w io.Writer, method, uri string, payload interface{}) error {
+	parsed, err := url.Parse(uri)
 	if err != nil {
 		return err
 	}
+	query := parsed.Query()
+	query.Del("access_token")
+	parsed.RawQuery = query.Encode()
 
-	if response.StatusCode != http.StatusOK {
-		return fmt.Errorf("the open issue endpoint returned %v", response.StatusCode)
+	description := fmt.Sprintf("%v %v", method, strings.TrimPrefix(parsed.String(), githubRoot))
+	if payload != nil {
+		payloadJSON, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		description += " " + string(payloadJSON)
 	}
-
-	return nil
+	_, err = fmt.Fprintln(w, description)
+	return err
 }
 
 func createDefaultRequest(method, uri string) (*http.Request, error) {
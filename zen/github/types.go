@@ -1,17 +1,215 @@
 package github
 
+import "time"
+
 // Repository represents a github repository.
 type Repository struct {
-	ID int `json:"id"`
+	ID            int    `json:"id"`
+	Name          string `json:"name"`
+	FullName      string `json:"full_name"`
+	Owner         User   `json:"owner"`
+	DefaultBranch string `json:"default_branch"`
 }
 
-// Issue represents a github issue.
+// Issue represents a github issue. The issues endpoints also return pull requests, which have a PullRequest.
 type Issue struct {
-	Number    int    `json:"number"`
-	State     string `json:"state"`
-	Title     string `json:"title"`
-	Assignee  User   `json:"assignee"`
-	Assignees []User `json:"assignees"`
+	Number      int               `json:"number"`
+	State       string            `json:"state"`
+	Title       string            `json:"title"`
+	Body        string            `json:"body"`
+	User        User              `json:"user"`
+	Assignee    User              `json:"assignee"`
+	Assignees   []User            `json:"assignees"`
+	Labels      []Label           `json:"labels"`
+	Milestone   *Milestone        `json:"milestone"`
+	HTMLURL     string            `json:"html_url"`
+	Draft       bool              `json:"draft"`
+	PullRequest *IssuePullRequest `json:"pull_request"`
+	// Repository is only returned for issues in timeline events.
+	Repository *Repository `json:"repository"`
+	CreatedAt  time.Time   `json:"created_at"`
+	ClosedAt   *time.Time  `json:"closed_at"`
+}
+
+// IsPullRequest returns true if the issue is a pull request.
+func (i *Issue) IsPullRequest() bool {
+	return i.PullRequest != nil
+}
+
+// IssuePullRequest is returned by the issues endpoints for issues that are pull requests.
+type IssuePullRequest struct {
+	URL      string     `json:"url"`
+	HTMLURL  string     `json:"html_url"`
+	MergedAt *time.Time `json:"merged_at"`
+}
+
+// PullRequest represents a github pull request.
+type PullRequest struct {
+	Number    int        `json:"number"`
+	State     string     `json:"state"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	User      User       `json:"user"`
+	Draft     bool       `json:"draft"`
+	Merged    bool       `json:"merged"`
+	HTMLURL   string     `json:"html_url"`
+	Head      Branch     `json:"head"`
+	Base      Branch     `json:"base"`
+	CreatedAt time.Time  `json:"created_at"`
+	MergedAt  *time.Time `json:"merged_at"`
+}
+
+// NewPullRequest represents the fields that can be supplied when opening a pull request.
+type NewPullRequest struct {
+	Title string `json:"title"`
+	Head  string `json:"head"`
+	Base  string `json:"base"`
+	Body  string `json:"body,omitempty"`
+	Draft bool   `json:"draft,omitempty"`
+}
+
+// ReviewRequest represents the users and teams to request reviews from.
+type ReviewRequest struct {
+	Reviewers     []string `json:"reviewers,omitempty"`
+	TeamReviewers []string `json:"team_reviewers,omitempty"`
+}
+
+// Branch represents the head or base branch of a pull request.
+type Branch struct {
+	Ref string `json:"ref"`
+	SHA string `json:"sha"`
+}
+
+// Review represents a review of a pull request.
+type Review struct {
+	ID          int       `json:"id"`
+	User        User      `json:"user"`
+	State       string    `json:"state"`
+	SubmittedAt time.Time `json:"submitted_at"`
+}
+
+// Review states.
+const (
+	ReviewApproved         = "APPROVED"
+	ReviewChangesRequested = "CHANGES_REQUESTED"
+	ReviewCommented        = "COMMENTED"
+	ReviewDismissed        = "DISMISSED"
+)
+
+// CombinedStatus represents the combined commit status of a ref.
+type CombinedStatus struct {
+	State      string `json:"state"`
+	TotalCount int    `json:"total_count"`
+}
+
+// CheckRuns represents the check runs for a ref.
+type CheckRuns struct {
+	TotalCount int        `json:"total_count"`
+	List       []CheckRun `json:"check_runs"`
+}
+
+// CheckRun represents a single check run, such as a CI job.
+type CheckRun struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Conclusion string `json:"conclusion"`
+}
+
+// TimelineEvent represents an event in the timeline of an issue.
+type TimelineEvent struct {
+	Event     string          `json:"event"`
+	Actor     User            `json:"actor"`
+	Source    *TimelineSource `json:"source"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// TimelineSource is the issue or pull request that a cross-referenced event came from.
+type TimelineSource struct {
+	Type  string `json:"type"`
+	Issue *Issue `json:"issue"`
+}
+
+// NewIssue represents the fields that can be supplied when creating a github issue.
+type NewIssue struct {
+	Title     string   `json:"title"`
+	Body      string   `json:"body,omitempty"`
+	Labels    []string `json:"labels,omitempty"`
+	Assignees []string `json:"assignees,omitempty"`
+	Milestone int      `json:"milestone,omitempty"`
+}
+
+// IssuePatch represents a partial update to a github issue. Fields that are nil are left unchanged.
+type IssuePatch struct {
+	Title       *string `json:"title,omitempty"`
+	Body        *string `json:"body,omitempty"`
+	State       *string `json:"state,omitempty"`
+	StateReason *string `json:"state_reason,omitempty"`
+	Milestone   *int    `json:"milestone,omitempty"`
+}
+
+// Issue states and state reasons.
+const (
+	StateOpen             = "open"
+	StateClosed           = "closed"
+	StateReasonCompleted  = "completed"
+	StateReasonNotPlanned = "not_planned"
+	StateReasonDuplicate  = "duplicate"
+	StateReasonReopened   = "reopened"
+)
+
+// Comment represents a comment on a github issue.
+type Comment struct {
+	ID   int    `json:"id"`
+	Body string `json:"body"`
+	User User   `json:"user"`
+	// IssueURL is the API URL of the issue that the comment is on, which ends with the issue's number.
+	IssueURL  string    `json:"issue_url"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// IssueEvent represents an event on an issue, such as it being closed or assigned.
+type IssueEvent struct {
+	ID        int       `json:"id"`
+	Event     string    `json:"event"`
+	Actor     User      `json:"actor"`
+	Assignee  *User     `json:"assignee"`
+	Issue     *Issue    `json:"issue"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Content represents a file or directory in a github repository.
+type Content struct {
+	Name     string `json:"name"`
+	Path     string `json:"path"`
+	Type     string `json:"type"`
+	Encoding string `json:"encoding"`
+	Content  string `json:"content"`
+}
+
+// Label represents a github label.
+type Label struct {
+	Name        string `json:"name"`
+	Color       string `json:"color"`
+	Description string `json:"description"`
+}
+
+// IssueLabels represents a list of label names associated with an issue.
+type IssueLabels struct {
+	List []string `json:"labels"`
+}
+
+// Milestone represents a github milestone.
+type Milestone struct {
+	Number       int        `json:"number"`
+	Title        string     `json:"title"`
+	Description  string     `json:"description"`
+	State        string     `json:"state"`
+	OpenIssues   int        `json:"open_issues"`
+	ClosedIssues int        `json:"closed_issues"`
+	CreatedAt    time.Time  `json:"created_at"`
+	DueOn        *time.Time `json:"due_on"`
+	ClosedAt     *time.Time `json:"closed_at"`
 }
 
 // User represents a github user.
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// hookMarker identifies the hooks that zen installed, so they can be replaced without --force.
+const hookMarker = "# Installed by zen hook install."
+
+// commitMsgHook is the commit-msg hook, which passes the message file to zen.
+const commitMsgHook = `#!/bin/sh
+` + hookMarker + `
+exec zen hook commit-msg "$1"
+`
+
+// checkCommitIssuesKey is the workflow setting that makes the commit-msg hook check the issues that a message
+// refers to against the board.
+const checkCommitIssuesKey = "check_commit_issues"
+
+// boardCacheTTL is how long the commit-msg hook uses the board that it cached before fetching it again, and
+// boardFetchTimeout is how long fetching it can take before the issues are not checked, so commits are not slowed.
+const (
+	boardCacheTTL     = 5 * time.Minute
+	boardFetchTimeout = 4 * time.Second
+)
+
+// autoCommentChars are the characters that git picks the comment character from when core.commentChar is "auto".
+const autoCommentChars = "#;@!$%^&|:"
+
+// skippedCommitPattern matches the messages that do not need to refer to an issue, since git or a rebase wrote them.
+var skippedCommitPattern = regexp.MustCompile(`^(Merge |Revert "|fixup! |squash! |amend! )`)
+
+// issueReferencePattern matches a reference to an issue (#123, repo#123 or owner/repo#123), capturing the owner
+// (with its trailing slash), the repository and the number.
+var issueReferencePattern = regexp.MustCompile(`(?:^|[^A-Za-z0-9_.#/-])(?:([A-Za-z0-9_.-]+/)?([A-Za-z0-9_.-]+))?#([0-9]+)\b`)
+
+// cachedBoard is the pipeline of each open issue on the board, as cached by the commit-msg hook.
+type cachedBoard struct {
+	FetchedAt time.Time      `json:"fetched_at"`
+	Pipelines map[int]string `json:"pipelines"`
+}
+
+// InstallHook writes the commit-msg hook into the local repository. An existing hook that zen did not install is
+// only replaced if force is true.
+func (a *Actions) InstallHook(force bool) error {
+	repo, err := a.openRepo()
+	if err != nil {
+		return err
+	}
+	dir, err := repo.HooksDir()
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, "commit-msg")
+	existing, err := ioutil.ReadFile(path)
+	if err == nil && !strings.Contains(string(existing), hookMarker) && !force {
+		return fmt.Errorf("%v already exists; use --force to replace it", path)
+	}
+
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+	err = ioutil.WriteFile(path, []byte(commitMsgHook), 0755)
+	if err != nil {
+		return err
+	}
+	// WriteFile does not change the mode of an existing file.
+	err = os.Chmod(path, 0755)
+	if err == nil {
+		fmt.Printf("Installed the commit-msg hook in %v.\n", path)
+	}
+	return err
+}
+
+// CommitMsgHook checks that the commit message in the specified file refers to an issue. If it does not, the issue
+// of the checked out branch is added to it. If check_commit_issues is set in the workflow section of the config
+// file, issues that are closed or in the backlog are rejected, using the cached board.
+func (a *Actions) CommitMsgHook(file string) error {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	message := string(content)
+	repo, err := a.openRepo()
+	if err != nil {
+		return err
+	}
+	commentChar, err := repo.CommentChar()
+	if err != nil {
+		return err
+	}
+	if commentChar == "auto" {
+		commentChar = autoCommentChar(message)
+	}
+	text := stripComments(message, commentChar)
+	if strings.TrimSpace(text) == "" || skippedCommitPattern.MatchString(text) {
+		return nil
+	}
+
+	issues := a.referencedIssues(text)
+	if len(issues) == 0 {
+		issue, err := a.CurrentIssue()
+		if err != nil {
+			return fmt.Errorf("the commit message does not refer to an issue (i.e. #123), and %v", err)
+		}
+		err = ioutil.WriteFile(file, []byte(addIssueReference(message, issue, commentChar)), 0644)
+		if err != nil {
+			return err
+		}
+		issues = []int{issue}
+	}
+
+	check, err := workflowSetting(checkCommitIssuesKey, "false")
+	if err != nil {
+		return err
+	}
+	if enabled, _ := strconv.ParseBool(check); enabled {
+		return a.checkCommitIssues(issues)
+	}
+	return nil
+}
+
+// referencedIssues returns the numbers of the issues in the current repository that the text refers to, either as
+// #123 or as owner/repo#123.
+func (a *Actions) referencedIssues(text string) []int {
+	issues := []int{}
+	for _, match := range issueReferencePattern.FindAllStringSubmatch(text, -1) {
+		owner, repo := strings.TrimSuffix(match[1], "/"), match[2]
+		if repo != "" && !strings.EqualFold(repo, a.githubAPI.RepoName) {
+			continue
+		}
+		if owner != "" && !strings.EqualFold(owner, a.githubAPI.Owner()) {
+			continue
+		}
+		number, err := strconv.Atoi(match[3])
+		if err == nil && number > 0 {
+			issues = append(issues, number)
+		}
+	}
+	return issues
+}
+
+// checkCommitIssues rejects references to issues that are not open on the board, or are in the backlog. The board
+// is cached, so the check does not slow down every commit; if it cannot be fetched, the issues are not checked.
+func (a *Actions) checkCommitIssues(issues []int) error {
+	pipelines := a.boardPipelines()
+	if len(pipelines) == 0 {
+		return nil
+	}
+	for _, issue := range issues {
+		pipeline, ok := pipelines[issue]
+		switch {
+		case !ok:
+			return fmt.Errorf("issue %v is closed (or is not on the board); refer to an open issue", issue)
+		case pipeline == "Backlog":
+			return fmt.Errorf("issue %v is in the backlog; start it (zen start %v) before committing to it", issue, issue)
+		}
+	}
+	return nil
+}
+
+// boardPipelines returns the pipeline of each issue on the board, from the cache if it was fetched recently. If the
+// board cannot be fetched in time, the stale cached board (or nil) is returned.
+func (a *Actions) boardPipelines() map[int]string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return nil
+	}
+	path := filepath.Join(dir, "zen", "hook", filepath.FromSlash(a.githubAPI.FullName()), "board.json")
+	cached := &cachedBoard{}
+	if content, err := ioutil.ReadFile(path); err == nil && json.Unmarshal(content, cached) == nil &&
+		time.Since(cached.FetchedAt) < boardCacheTTL {
+		return cached.Pipelines
+	}
+
+	fetched := make(chan *cachedBoard, 1)
+	go func() {
+		pipelines, err := a.zenHubAPI.GetPipelines()
+		if err != nil {
+			fetched <- nil
+			return
+		}
+		board := &cachedBoard{FetchedAt: time.Now(), Pipelines: map[int]string{}}
+		for _, pipeline := range pipelines.List {
+			for _, issue := range pipeline.Issues {
+				board.Pipelines[issue.IssueNumber] = pipeline.Name
+			}
+		}
+		fetched <- board
+	}()
+	select {
+	case board := <-fetched:
+		if board == nil {
+			return cached.Pipelines
+		}
+		// Failing to cache the board only means that it is fetched again next time.
+		if content, err := json.Marshal(board); err == nil && os.MkdirAll(filepath.Dir(path), 0700) == nil {
+			ioutil.WriteFile(path, content, 0600)
+		}
+		return board.Pipelines
+	case <-time.After(boardFetchTimeout):
+		return cached.Pipelines
+	}
+}
+
+// autoCommentChar returns the comment character that git picks for the message when core.commentChar is "auto":
+// the first of its candidates that no line of the message starts with.
+func autoCommentChar(message string) string {
+	for _, candidate := range autoCommentChars {
+		used := false
+		for _, line := range strings.Split(message, "\n") {
+			if strings.HasPrefix(line, string(candidate)) {
+				used = true
+				break
+			}
+		}
+		if !used {
+			return string(candidate)
+		}
+	}
+	return "#"
+}
+
+// stripComments removes the lines that git strips from a commit message, which are the comment lines (starting
+// with the comment character) and everything after the scissors line left by "git commit -v".
+func stripComments(message, commentChar string) string {
+	scissors := commentChar + " ------------------------ >8 ------------------------"
+	lines := []string{}
+	for _, line := range strings.Split(message, "\n") {
+		if strings.HasPrefix(line, scissors) {
+			break
+		}
+		if !strings.HasPrefix(line, commentChar) {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// addIssueReference adds a reference to the issue to the end of the message, before any comment lines, as a
+// trailer separated from the body by a blank line.
+func addIssueReference(message string, issue int, commentChar string) string {
+	lines := strings.Split(message, "\n")
+	end := len(lines)
+	for i, line := range lines {
+		if strings.HasPrefix(line, commentChar) {
+			end = i
+			break
+		}
+	}
+	body := strings.TrimRight(strings.Join(lines[:end], "\n"), "\n")
+	rest := strings.Join(lines[end:], "\n")
+	result := fmt.Sprintf("%v\n\nRefs #%v\n", body, issue)
+	if rest != "" {
+		result += "\n" + rest
+	}
+	return result
+}
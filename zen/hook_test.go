@@ -0,0 +1,105 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/eltorocorp/zencli/zen/github"
+)
+
+func TestReferencedIssues(t *testing.T) {
+	a := &Actions{githubAPI: github.New("", "zencli", "eltorocorp")}
+	tests := []struct {
+		text string
+		want []int
+	}{
+		{"Fix the login page", []int{}},
+		{"Fix the login page (#12)", []int{12}},
+		{"#12 and #13", []int{12, 13}},
+		{"Refs zencli#12, eltorocorp/zencli#13, ELTOROCORP/ZENCLI#14", []int{12, 13, 14}},
+		{"Refs api#12 and someone/zencli#13", []int{}},
+		{"Issue #0 is not an issue", []int{}},
+		{"a#12, ##12 and x/#12 are not references", []int{}},
+		{"See https://example.com/page#12", []int{}},
+		{"Line one\n#7 at the start of a line", []int{7}},
+	}
+	for _, test := range tests {
+		t.Run(test.text, func(t *testing.T) {
+			if got := a.referencedIssues(test.text); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestStripComments(t *testing.T) {
+	tests := []struct {
+		name        string
+		message     string
+		commentChar string
+		want        string
+	}{
+		{"no comments", "Subject\n\nBody\n", "#", "Subject\n\nBody\n"},
+		{"comments", "Subject\n# Please enter the commit message\n#\nBody", "#", "Subject\nBody"},
+		{
+			"scissors",
+			"Subject\n# ------------------------ >8 ------------------------\n# Do not modify\ndiff --git a/x b/x\n",
+			"#",
+			"Subject",
+		},
+		{"other comment character", "Subject\n#12 is fixed\n; comment\n", ";", "Subject\n#12 is fixed\n"},
+		{"other scissors", "Subject\n; ------------------------ >8 ------------------------\n#12\n", ";", "Subject"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := stripComments(test.message, test.commentChar); got != test.want {
+				t.Errorf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestAddIssueReference(t *testing.T) {
+	tests := []struct {
+		name        string
+		message     string
+		commentChar string
+		want        string
+	}{
+		{"subject only", "Fix the login page\n", "#", "Fix the login page\n\nRefs #12\n"},
+		{"body", "Fix the login page\n\nIt was broken.\n\n\n", "#", "Fix the login page\n\nIt was broken.\n\nRefs #12\n"},
+		{
+			"before comments",
+			"Fix the login page\n# Please enter the commit message\n",
+			"#",
+			"Fix the login page\n\nRefs #12\n\n# Please enter the commit message\n",
+		},
+		{
+			"other comment character",
+			"Fix #3 first\n; comment\n",
+			";",
+			"Fix #3 first\n\nRefs #12\n\n; comment\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := addIssueReference(test.message, 12, test.commentChar); got != test.want {
+				t.Errorf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestAutoCommentChar(t *testing.T) {
+	tests := map[string]string{
+		"Subject\n\nBody":              "#",
+		"Subject\n#12 is fixed":        ";",
+		"#12\n;x\n@y\n!z":              "$",
+		"#\n;\n@\n!\n$\n%\n^\n&\n|\n:": "#",
+	}
+	for message, want := range tests {
+		if got := autoCommentChar(message); got != want {
+			t.Errorf("autoCommentChar(%q): expected %q, got %q", message, want, got)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package issuetemplate
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/eltorocorp/zencli/zen/yaml"
+)
+
+// Dir is the path of the issue template directory, relative to the root of a repository.
+const Dir = ".github/ISSUE_TEMPLATE"
+
+// Template represents a github issue template.
+type Template struct {
+	// Key is the file name of the template without its extension (i.e. "bug_report" for bug_report.md).
+	Key       string
+	Name      string
+	About     string
+	Title     string
+	Labels    []string
+	Assignees []string
+	Body      string
+}
+
+// IsTemplateFile reports whether the specified file name is the name of a markdown issue template.
+func IsTemplateFile(fileName string) bool {
+	return strings.ToLower(filepath.Ext(fileName)) == ".md"
+}
+
+// Parse parses the template with the specified file name. The front matter of the template (if any) supplies the
+// template's defaults, and the remainder of the file is the body.
+func Parse(fileName string, data []byte) (*Template, error) {
+	template := &Template{
+		Key: strings.TrimSuffix(filepath.Base(fileName), filepath.Ext(fileName)),
+	}
+
+	text := strings.Replace(string(data), "\r\n", "\n", -1)
+	if strings.HasPrefix(text, "---\n") {
+		// The front matter ends at the next line that is just "---", which may be the very next line.
+		lines := strings.SplitAfter(text, "\n")
+		end := -1
+		for i := 1; i < len(lines) && end < 0; i++ {
+			if strings.TrimRight(lines[i], " \t\n") == "---" {
+				end = i
+			}
+		}
+		if end < 0 {
+			return nil, fmt.Errorf("the front matter in template '%v' is not terminated", fileName)
+		}
+		// The opening "---" is kept (the YAML parser skips it) so errors have the line numbers of the file.
+		frontMatter := strings.Join(lines[:end], "")
+		text = strings.Join(lines[end+1:], "")
+
+		value, err := yaml.Unmarshal([]byte(frontMatter))
+		if err != nil {
+			return nil, fmt.Errorf("template '%v': %v", fileName, err)
+		}
+		fields, _ := value.(map[string]interface{})
+		template.Name = yaml.String(fields["name"])
+		template.About = yaml.String(fields["about"])
+		template.Title = yaml.String(fields["title"])
+		template.Labels = yaml.Strings(fields["labels"])
+		template.Assignees = yaml.Strings(fields["assignees"])
+	}
+
+	if template.Name == "" {
+		template.Name = template.Key
+	}
+	template.Body = strings.TrimSpace(text)
+	return template, nil
+}
+
+// LoadLocal loads the templates from the local checkout that contains the current working directory. If the
+// current directory is not in a checkout, or the checkout has no issue templates, this method returns nil.
+func LoadLocal() ([]*Template, error) {
+	dir, err := findLocalDir()
+	if err != nil || dir == "" {
+		return nil, err
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	templates := []*Template{}
+	for _, file := range files {
+		if file.IsDir() || !IsTemplateFile(file.Name()) {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		template, err := Parse(file.Name(), data)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, template)
+	}
+	return templates, nil
+}
+
+// Find returns the template whose key or name matches the supplied name (ignoring case). If none match exactly, the
+// template whose key or name starts with the supplied name is returned (i.e. "bug" finds bug_report.md), as long as
+// only one does.
+func Find(templates []*Template, name string) (*Template, error) {
+	if len(templates) == 0 {
+		return nil, errors.New("no issue templates are available for this repository")
+	}
+	for _, template := range templates {
+		if strings.EqualFold(template.Key, name) || strings.EqualFold(template.Name, name) {
+			return template, nil
+		}
+	}
+
+	prefix := strings.ToLower(name)
+	matches, keys := []*Template{}, []string{}
+	for _, template := range templates {
+		if strings.HasPrefix(strings.ToLower(template.Key), prefix) || strings.HasPrefix(strings.ToLower(template.Name), prefix) {
+			matches = append(matches, template)
+		}
+		keys = append(keys, template.Key)
+	}
+	switch {
+	case len(matches) == 1 && name != "":
+		return matches[0], nil
+	case len(matches) > 1 && name != "":
+		matchingKeys := []string{}
+		for _, template := range matches {
+			matchingKeys = append(matchingKeys, template.Key)
+		}
+		return nil, fmt.Errorf("template '%v' is ambiguous. Matching templates: %v", name, strings.Join(matchingKeys, ", "))
+	}
+	return nil, fmt.Errorf("template '%v' does not exist. Available templates: %v", name, strings.Join(keys, ", "))
+}
+
+// findLocalDir walks up from the current working directory looking for the root of a git checkout, and returns
+// the path to the checkout's issue template directory if it exists.
+func findLocalDir() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			templateDir := filepath.Join(dir, filepath.FromSlash(Dir))
+			if info, err := os.Stat(templateDir); err == nil && info.IsDir() {
+				return templateDir, nil
+			}
+			return "", nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
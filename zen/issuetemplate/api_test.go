@@ -0,0 +1,122 @@
+package issuetemplate
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		data     string
+		want     *Template
+	}{
+		{
+			name:     "front matter",
+			fileName: "bug_report.md",
+			data:     "---\nname: Bug report\nabout: Report a bug\ntitle: '[BUG] '\nlabels: bug, triage\nassignees:\n  - octocat\n---\n\n**Describe the bug**\n",
+			want: &Template{Key: "bug_report", Name: "Bug report", About: "Report a bug", Title: "[BUG] ",
+				Labels: []string{"bug", "triage"}, Assignees: []string{"octocat"}, Body: "**Describe the bug**"},
+		},
+		{
+			name:     "no front matter",
+			fileName: "feature.md",
+			data:     "Describe the feature.\n",
+			want:     &Template{Key: "feature", Name: "feature", Body: "Describe the feature."},
+		},
+		{
+			name:     "empty front matter",
+			fileName: "task.md",
+			data:     "---\n---\nDo the thing.\n",
+			want:     &Template{Key: "task", Name: "task", Labels: []string{}, Assignees: []string{}, Body: "Do the thing."},
+		},
+		{
+			name:     "front matter only",
+			fileName: "empty.md",
+			data:     "---\nname: Empty\n---",
+			want:     &Template{Key: "empty", Name: "Empty", Labels: []string{}, Assignees: []string{}},
+		},
+		{
+			name:     "windows line endings and a rule in the body",
+			fileName: "rule.md",
+			data:     "---\r\nname: Rule\r\n---\r\nabove\r\n---\r\nbelow\r\n",
+			want:     &Template{Key: "rule", Name: "Rule", Labels: []string{}, Assignees: []string{}, Body: "above\n---\nbelow"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Parse(test.fileName, []byte(test.data))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("expected %+v, got %+v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"unterminated", "---\nname: x\n", "the front matter in template 't.md' is not terminated"},
+		{"invalid yaml", "---\nname: [x\n---\n", "template 't.md': line 2: unterminated sequence"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Parse("t.md", []byte(test.data))
+			if err == nil || err.Error() != test.want {
+				t.Errorf("expected the error %q, got %v", test.want, err)
+			}
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	templates := []*Template{
+		{Key: "bug_report", Name: "Bug report"},
+		{Key: "feature_request", Name: "Feature request"},
+		{Key: "feature_flag", Name: "Feature flag"},
+		{Key: "docs", Name: "Documentation"},
+	}
+	tests := []struct {
+		name    string
+		want    string
+		wantErr string
+	}{
+		{name: "bug_report", want: "bug_report"},
+		{name: "BUG REPORT", want: "bug_report"},
+		{name: "bug", want: "bug_report"},
+		{name: "document", want: "docs"},
+		{name: "feature_r", want: "feature_request"},
+		{name: "feature", wantErr: "template 'feature' is ambiguous. Matching templates: feature_request, feature_flag"},
+		{name: "security", wantErr: "template 'security' does not exist. Available templates: bug_report, feature_request, feature_flag, docs"},
+		{name: "", wantErr: "template '' does not exist"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			template, err := Find(templates, test.name)
+			if test.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), test.wantErr) {
+					t.Errorf("expected the error %q, got %v", test.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if template.Key != test.want {
+				t.Errorf("expected %v, got %v", test.want, template.Key)
+			}
+		})
+	}
+
+	if _, err := Find(nil, "bug"); err == nil || err.Error() != "no issue templates are available for this repository" {
+		t.Errorf("expected an error for no templates, got %v", err)
+	}
+}
@@ -0,0 +1,2 @@
+// Package issuetemplate loads and parses github issue templates (.github/ISSUE_TEMPLATE/*.md).
+package issuetemplate
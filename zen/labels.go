@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/eltorocorp/zencli/zen/github"
+	"github.com/eltorocorp/zencli/zen/yaml"
+)
+
+// labelChange represents a single change required to make a repository's labels match a label definition file.
+type labelChange struct {
+	action string
+	name   string
+	label  *github.Label
+}
+
+// loadLabelDefinitions reads a label definition file. The file can either be a list of labels, or a mapping with
+// a "labels" key containing the list. Each label has a name, a color and (optionally) a description:
+//
+//	labels:
+//	  - name: bug
+//	    color: d73a4a
+//	    description: Something isn't working
+func loadLabelDefinitions(path string) ([]*github.Label, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	document, err := yaml.Unmarshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", path, err)
+	}
+	if mapping, ok := document.(map[string]interface{}); ok {
+		document = mapping["labels"]
+	}
+	items, ok := document.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%v: expected a list of labels", path)
+	}
+
+	labels := []*github.Label{}
+	seen := map[string]bool{}
+	for i, item := range items {
+		fields, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%v: label %v is not a mapping", path, i+1)
+		}
+		label := &github.Label{
+			Name:        strings.TrimSpace(yaml.String(fields["name"])),
+			Color:       strings.ToLower(strings.TrimPrefix(yaml.String(fields["color"]), "#")),
+			Description: yaml.String(fields["description"]),
+		}
+		if label.Name == "" {
+			return nil, fmt.Errorf("%v: label %v has no name", path, i+1)
+		}
+		if _, err := strconv.ParseUint(label.Color, 16, 32); err != nil || len(label.Color) != 6 {
+			return nil, fmt.Errorf("%v: label '%v' must have a six digit hex color", path, label.Name)
+		}
+		if seen[strings.ToLower(label.Name)] {
+			return nil, fmt.Errorf("%v: label '%v' is defined more than once", path, label.Name)
+		}
+		seen[strings.ToLower(label.Name)] = true
+		labels = append(labels, label)
+	}
+	return labels, nil
+}
+
+// planLabelChanges returns the creates, updates and deletes required to make the existing labels match the
+// desired labels. Labels are matched by name, ignoring case.
+func planLabelChanges(existing, desired []*github.Label) []*labelChange {
+	changes := []*labelChange{}
+	existingByName := map[string]*github.Label{}
+	for _, label := range existing {
+		existingByName[strings.ToLower(label.Name)] = label
+	}
+	desiredByName := map[string]*github.Label{}
+	for _, label := range desired {
+		desiredByName[strings.ToLower(label.Name)] = label
+	}
+
+	for _, label := range desired {
+		current, ok := existingByName[strings.ToLower(label.Name)]
+		if !ok {
+			changes = append(changes, &labelChange{action: "create", name: label.Name, label: label})
+			continue
+		}
+		if current.Name != label.Name || !strings.EqualFold(current.Color, label.Color) || current.Description != label.Description {
+			changes = append(changes, &labelChange{action: "update", name: current.Name, label: label})
+		}
+	}
+
+	for _, label := range existing {
+		if _, ok := desiredByName[strings.ToLower(label.Name)]; !ok {
+			changes = append(changes, &labelChange{action: "delete", name: label.Name})
+		}
+	}
+
+	order := map[string]int{"create": 0, "update": 1, "delete": 2}
+	sort.SliceStable(changes, func(i, j int) bool {
+		return order[changes[i].action] < order[changes[j].action]
+	})
+	return changes
+}
+
+// describeLabelUpdate describes the fields that an update changes.
+func describeLabelUpdate(existing []*github.Label, change *labelChange) string {
+	for _, current := range existing {
+		if current.Name != change.name {
+			continue
+		}
+		differences := []string{}
+		if current.Name != change.label.Name {
+			differences = append(differences, fmt.Sprintf("name: %v -> %v", current.Name, change.label.Name))
+		}
+		if !strings.EqualFold(current.Color, change.label.Color) {
+			differences = append(differences, fmt.Sprintf("color: #%v -> #%v", current.Color, change.label.Color))
+		}
+		if current.Description != change.label.Description {
+			differences = append(differences, fmt.Sprintf("description: %q -> %q", current.Description, change.label.Description))
+		}
+		return strings.Join(differences, ", ")
+	}
+	return ""
+}
+
+// swatch renders a label color as a block of color if stdout is a terminal (and NO_COLOR is not set). Otherwise
+// the color is rendered as a hex value.
+func swatch(color string) string {
+	value, err := strconv.ParseUint(color, 16, 32)
+	if err != nil || len(color) != 6 || !colorEnabled() {
+		return pr("#"+color, 8)
+	}
+	return fmt.Sprintf("\x1b[48;2;%v;%v;%vm      \x1b[0m  ", (value>>16)&0xff, (value>>8)&0xff, value&0xff)
+}
+
+func colorEnabled() bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
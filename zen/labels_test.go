@@ -0,0 +1,110 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/eltorocorp/zencli/zen/github"
+)
+
+func TestPlanLabelChanges(t *testing.T) {
+	label := func(name, color, description string) *github.Label {
+		return &github.Label{Name: name, Color: color, Description: description}
+	}
+	tests := []struct {
+		name     string
+		existing []*github.Label
+		desired  []*github.Label
+		want     []string
+	}{
+		{"no labels", nil, nil, []string{}},
+		{"unchanged", []*github.Label{label("bug", "d73a4a", "Broken")}, []*github.Label{label("bug", "D73A4A", "Broken")}, []string{}},
+		{"create", nil, []*github.Label{label("bug", "d73a4a", "")}, []string{"create bug"}},
+		{"delete", []*github.Label{label("wontfix", "ffffff", "")}, nil, []string{"delete wontfix"}},
+		{"recolor", []*github.Label{label("bug", "ffffff", "")}, []*github.Label{label("bug", "d73a4a", "")}, []string{"update bug"}},
+		{"describe", []*github.Label{label("bug", "d73a4a", "")}, []*github.Label{label("bug", "d73a4a", "Broken")}, []string{"update bug"}},
+		{"rename by case", []*github.Label{label("Bug", "d73a4a", "")}, []*github.Label{label("bug", "d73a4a", "")}, []string{"update Bug"}},
+		{
+			"creates, then updates, then deletes",
+			[]*github.Label{label("wontfix", "ffffff", ""), label("bug", "ffffff", ""), label("docs", "0075ca", "")},
+			[]*github.Label{label("bug", "d73a4a", ""), label("urgent", "b60205", ""), label("docs", "0075ca", "")},
+			[]string{"create urgent", "update bug", "delete wontfix"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := []string{}
+			for _, change := range planLabelChanges(test.existing, test.desired) {
+				got = append(got, change.action+" "+change.name)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestDescribeLabelUpdate(t *testing.T) {
+	existing := []*github.Label{{Name: "Bug", Color: "ffffff", Description: "Broken"}}
+	change := &labelChange{action: "update", name: "Bug", label: &github.Label{Name: "bug", Color: "d73a4a", Description: "Broken"}}
+	want := "name: Bug -> bug, color: #ffffff -> #d73a4a"
+	if got := describeLabelUpdate(existing, change); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestLoadLabelDefinitions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "zen-labels")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name string
+		file string
+		want []*github.Label
+		err  string
+	}{
+		{
+			name: "list",
+			file: "- name: bug\n  color: '#D73A4A'\n  description: Something isn't working\n",
+			want: []*github.Label{{Name: "bug", Color: "d73a4a", Description: "Something isn't working"}},
+		},
+		{
+			name: "mapping",
+			file: "labels:\n  - name: docs\n    color: 0075ca\n",
+			want: []*github.Label{{Name: "docs", Color: "0075ca"}},
+		},
+		{name: "not a list", file: "name: bug\n", err: "expected a list of labels"},
+		{name: "not a mapping", file: "- bug\n", err: "label 1 is not a mapping"},
+		{name: "no name", file: "- color: d73a4a\n", err: "label 1 has no name"},
+		{name: "bad color", file: "- name: bug\n  color: red\n", err: "label 'bug' must have a six digit hex color"},
+		{name: "duplicate", file: "- name: bug\n  color: d73a4a\n- name: Bug\n  color: d73a4a\n", err: "label 'Bug' is defined more than once"},
+		{name: "invalid yaml", file: "- name: 'bug\n", err: "line 1: unterminated string"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(dir, test.name+".yml")
+			if err := ioutil.WriteFile(path, []byte(test.file), 0644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := loadLabelDefinitions(path)
+			if test.err != "" {
+				if want := path + ": " + test.err; err == nil || err.Error() != want {
+					t.Errorf("expected %q, got %v", want, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("expected %+v, got %+v", test.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,329 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eltorocorp/zencli/zen/command"
+	"github.com/eltorocorp/zencli/zen/github"
+	"github.com/eltorocorp/zencli/zen/zenhub"
+)
+
+// closedPipeline is the name that refers to an issue being closed, whether or not the board has a Closed pipeline.
+const closedPipeline = "closed"
+
+// percentiles are the percentiles reported for each set of durations.
+var percentiles = []int{50, 85, 95}
+
+// pipelineStay is a period that an issue spent in a pipeline. left is zero if the issue is still in the pipeline.
+type pipelineStay struct {
+	pipeline string
+	entered  time.Time
+	left     time.Time
+	next     string
+}
+
+// cycleTime is the cycle and lead time of a single issue. Started and CycleTime are nil if the issue never entered
+// the "from" pipeline before it finished.
+type cycleTime struct {
+	Number    int        `json:"number"`
+	Title     string     `json:"title"`
+	Created   time.Time  `json:"created"`
+	Started   *time.Time `json:"started"`
+	Finished  time.Time  `json:"finished"`
+	CycleTime *float64   `json:"cycle_time_hours"`
+	LeadTime  float64    `json:"lead_time_hours"`
+}
+
+// durationStats are the percentiles of a set of durations, in hours.
+type durationStats struct {
+	Name        string          `json:"name"`
+	Count       int             `json:"count"`
+	Percentiles map[int]float64 `json:"percentiles_hours"`
+}
+
+// cycleTimeReport is the output of the cycle time report. Unstarted is the number of issues that finished without
+// entering the "from" pipeline, which are only included in the lead time.
+type cycleTimeReport struct {
+	From        string           `json:"from"`
+	To          string           `json:"to"`
+	Since       time.Time        `json:"since"`
+	Issues      []*cycleTime     `json:"issues"`
+	Unstarted   int              `json:"unstarted"`
+	CycleTime   *durationStats   `json:"cycle_time"`
+	LeadTime    *durationStats   `json:"lead_time"`
+	Transitions []*durationStats `json:"transitions"`
+}
+
+// CycleTime reports how long the issues that reached the "to" pipeline since the specified time took to get there
+// from the "from" pipeline (the cycle time) and from being created (the lead time), with percentiles. The time spent
+// in a pipeline before each transition is reported too. The pipeline history of each issue is reconstructed from
+// its ZenHub events. An issue that never entered the "from" pipeline has no cycle time, so it is only included in the
+// lead time, and the number of such issues is reported.
+func (a *Actions) CycleTime(options command.MetricsOptions) error {
+	report, err := a.cycleTimeReport(options)
+	if err != nil {
+		return err
+	}
+	switch options.Output {
+	case "csv":
+		return writeCycleTimeCSV(report)
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+	printCycleTime(report)
+	return nil
+}
+
+func (a *Actions) cycleTimeReport(options command.MetricsOptions) (*cycleTimeReport, error) {
+	since := options.Since
+	if since == "" {
+		since = "30d"
+	}
+	sinceTime, err := parseSince(since, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	from := options.From
+	if from == "" {
+		from, err = workflowSetting(inProgressPipelineKey, defaultInProgressPipeline)
+		if err != nil {
+			return nil, err
+		}
+	}
+	to := options.To
+	if to == "" {
+		to = closedPipeline
+	}
+	for _, pipeline := range []string{from, to} {
+		if !strings.EqualFold(pipeline, closedPipeline) {
+			_, err = a.zenHubAPI.GetPipelineID(pipeline)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	issues, err := a.metricsIssues(sinceTime)
+	if err != nil {
+		return nil, err
+	}
+	report := &cycleTimeReport{From: from, To: to, Since: sinceTime, Issues: []*cycleTime{}}
+	cycleTimes, leadTimes := []float64{}, []float64{}
+	transitions := map[string][]float64{}
+	for _, issue := range issues {
+		events, err := a.zenHubAPI.GetIssueEvents(issue.Number)
+		if err != nil {
+			return nil, err
+		}
+		history := pipelineHistory(issue, events)
+		finished := enteredAt(history, to)
+		if finished.IsZero() || finished.Before(sinceTime) {
+			continue
+		}
+		for _, stay := range history {
+			if !stay.left.IsZero() && !stay.left.Before(sinceTime) && !stay.left.After(finished) {
+				key := stay.pipeline + " -> " + stay.next
+				transitions[key] = append(transitions[key], hours(stay.left.Sub(stay.entered)))
+			}
+		}
+		item := &cycleTime{
+			Number:   issue.Number,
+			Title:    issue.Title,
+			Created:  issue.CreatedAt,
+			Finished: finished,
+			LeadTime: hours(finished.Sub(issue.CreatedAt)),
+		}
+		started := enteredAt(history, from)
+		if started.IsZero() || started.After(finished) {
+			report.Unstarted++
+		} else {
+			cycle := hours(finished.Sub(started))
+			item.Started, item.CycleTime = &started, &cycle
+			cycleTimes = append(cycleTimes, cycle)
+		}
+		report.Issues = append(report.Issues, item)
+		leadTimes = append(leadTimes, item.LeadTime)
+	}
+
+	sort.Slice(report.Issues, func(i, j int) bool { return report.Issues[i].Finished.Before(report.Issues[j].Finished) })
+	report.CycleTime = newDurationStats("cycle time", cycleTimes)
+	report.LeadTime = newDurationStats("lead time", leadTimes)
+	report.Transitions = []*durationStats{}
+	for _, key := range sortedDurationKeys(transitions) {
+		report.Transitions = append(report.Transitions, newDurationStats(key, transitions[key]))
+	}
+	return report, nil
+}
+
+// metricsIssues returns the issues that could have reached a pipeline since the specified time: those closed since
+// then, and those still open on the board.
+func (a *Actions) metricsIssues(since time.Time) ([]*github.Issue, error) {
+	closed, err := a.githubAPI.SearchIssues(fmt.Sprintf("is:issue closed:>=%v", since.UTC().Format(dateFormat)))
+	if err != nil {
+		return nil, err
+	}
+	open, err := a.githubAPI.GetIssuesForRepo()
+	if err != nil {
+		return nil, err
+	}
+	issues := []*github.Issue{}
+	found := map[int]bool{}
+	for _, issue := range append(closed, open...) {
+		if !found[issue.Number] && !issue.IsPullRequest() {
+			found[issue.Number] = true
+			issues = append(issues, issue)
+		}
+	}
+	return issues, nil
+}
+
+// pipelineHistory reconstructs the pipelines that an issue has been in from its ZenHub events (which are newest
+// first). The issue is assumed to have been in the first pipeline it was moved from since it was created. If the
+// issue is closed, its last stay ends when it was closed, in the "closed" pipeline.
+func pipelineHistory(issue *github.Issue, events []*zenhub.IssueEvent) []*pipelineStay {
+	history := []*pipelineStay{}
+	for i := len(events) - 1; i >= 0; i-- {
+		event := events[i]
+		if event.Type != "transferIssue" || event.ToPipeline == nil {
+			continue
+		}
+		if len(history) == 0 && event.FromPipeline != nil {
+			history = append(history, &pipelineStay{pipeline: event.FromPipeline.Name, entered: issue.CreatedAt})
+		}
+		if len(history) > 0 {
+			last := history[len(history)-1]
+			last.left, last.next = event.CreatedAt, event.ToPipeline.Name
+		}
+		history = append(history, &pipelineStay{pipeline: event.ToPipeline.Name, entered: event.CreatedAt})
+	}
+	if issue.ClosedAt != nil && issue.State == github.StateClosed {
+		if len(history) > 0 {
+			last := history[len(history)-1]
+			if strings.EqualFold(last.pipeline, closedPipeline) {
+				return history
+			}
+			if last.left.IsZero() {
+				last.left, last.next = *issue.ClosedAt, closedPipeline
+			}
+		}
+		history = append(history, &pipelineStay{pipeline: closedPipeline, entered: *issue.ClosedAt})
+	}
+	return history
+}
+
+// enteredAt returns when the issue first entered the pipeline, or the zero time if it never did.
+func enteredAt(history []*pipelineStay, pipeline string) time.Time {
+	for _, stay := range history {
+		if strings.EqualFold(stay.pipeline, pipeline) {
+			return stay.entered
+		}
+	}
+	return time.Time{}
+}
+
+func newDurationStats(name string, values []float64) *durationStats {
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+	stats := &durationStats{Name: name, Count: len(sorted), Percentiles: map[int]float64{}}
+	if len(sorted) == 0 {
+		return stats
+	}
+	for _, p := range percentiles {
+		// Nearest rank: the smallest value that at least p percent of the values are less than or equal to.
+		rank := int(math.Ceil(float64(p) / 100 * float64(len(sorted))))
+		if rank < 1 {
+			rank = 1
+		}
+		stats.Percentiles[p] = sorted[rank-1]
+	}
+	return stats
+}
+
+func sortedDurationKeys(values map[string][]float64) []string {
+	keys := []string{}
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func hours(d time.Duration) float64 {
+	return math.Round(d.Hours()*10) / 10
+}
+
+// formatHours formats a number of hours as days and hours (i.e. "3d 4h"), or hours and minutes if it is less than a
+// day.
+func formatHours(value float64) string {
+	d := time.Duration(value * float64(time.Hour))
+	if d < 24*time.Hour {
+		return fmt.Sprintf("%vh %vm", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return fmt.Sprintf("%vd %vh", int(d.Hours())/24, int(d.Hours())%24)
+}
+
+func printCycleTime(report *cycleTimeReport) {
+	fmt.Printf("Cycle time from %v to %v, for issues finished since %v (%v)\n", report.From, report.To, report.Since.Local().Format(dateFormat), len(report.Issues))
+	for _, item := range report.Issues {
+		cycle := "-"
+		if item.CycleTime != nil {
+			cycle = formatHours(*item.CycleTime)
+		}
+		fmt.Printf(" - %v%v%v%v\n", pr(strconv.Itoa(item.Number), 7), pr(cycle, 10), pr("lead "+formatHours(item.LeadTime), 16), item.Title)
+	}
+	if report.Unstarted > 0 {
+		fmt.Printf("\n%v of the issues never entered %v, so they have no cycle time and are only in the lead time.\n", report.Unstarted, report.From)
+	}
+
+	fmt.Printf("\n%v%v", pr("", 42), pr("count", 7))
+	for _, p := range percentiles {
+		fmt.Printf("%v", pr(fmt.Sprintf("p%v", p), 10))
+	}
+	fmt.Println()
+	for _, stats := range append([]*durationStats{report.CycleTime, report.LeadTime}, report.Transitions...) {
+		fmt.Printf("%v%v", pr(stats.Name, 42), pr(strconv.Itoa(stats.Count), 7))
+		for _, p := range percentiles {
+			value := "-"
+			if stats.Count > 0 {
+				value = formatHours(stats.Percentiles[p])
+			}
+			fmt.Printf("%v", pr(value, 10))
+		}
+		fmt.Println()
+	}
+}
+
+// writeCycleTimeCSV writes a row for each issue, so the durations can be analyzed in a spreadsheet.
+func writeCycleTimeCSV(report *cycleTimeReport) error {
+	w := csv.NewWriter(os.Stdout)
+	w.Write([]string{"number", "title", "created", "started", "finished", "cycle_time_hours", "lead_time_hours"})
+	for _, item := range report.Issues {
+		// The start and cycle time are left empty for issues that never entered the "from" pipeline.
+		started, cycle := "", ""
+		if item.CycleTime != nil {
+			started = item.Started.Format(time.RFC3339)
+			cycle = strconv.FormatFloat(*item.CycleTime, 'f', 1, 64)
+		}
+		w.Write([]string{
+			strconv.Itoa(item.Number),
+			item.Title,
+			item.Created.Format(time.RFC3339),
+			started,
+			item.Finished.Format(time.RFC3339),
+			cycle,
+			strconv.FormatFloat(item.LeadTime, 'f', 1, 64),
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/eltorocorp/zencli/zen/github"
+	"github.com/eltorocorp/zencli/zen/zenhub"
+)
+
+func TestNewDurationStats(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   map[int]float64
+	}{
+		{"empty", nil, map[int]float64{}},
+		{"single", []float64{7}, map[int]float64{50: 7, 85: 7, 95: 7}},
+		{"unsorted", []float64{5, 1, 4, 2, 3}, map[int]float64{50: 3, 85: 5, 95: 5}},
+		{"twenty", []float64{20, 19, 18, 17, 16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1}, map[int]float64{50: 10, 85: 17, 95: 19}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stats := newDurationStats("test", test.values)
+			if stats.Count != len(test.values) {
+				t.Errorf("expected a count of %v, got %v", len(test.values), stats.Count)
+			}
+			if !reflect.DeepEqual(stats.Percentiles, test.want) {
+				t.Errorf("expected %v, got %v", test.want, stats.Percentiles)
+			}
+		})
+	}
+
+	values := []float64{3, 1, 2}
+	newDurationStats("test", values)
+	if !reflect.DeepEqual(values, []float64{3, 1, 2}) {
+		t.Errorf("expected the values to be left unsorted, got %v", values)
+	}
+}
+
+func TestPipelineHistory(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2018, 1, d, 0, 0, 0, 0, time.UTC) }
+	move := func(d int, from, to string) *zenhub.IssueEvent {
+		event := &zenhub.IssueEvent{Type: "transferIssue", CreatedAt: day(d), ToPipeline: &zenhub.EventPipeline{Name: to}}
+		if from != "" {
+			event.FromPipeline = &zenhub.EventPipeline{Name: from}
+		}
+		return event
+	}
+	closedAt := day(9)
+
+	tests := []struct {
+		name   string
+		issue  *github.Issue
+		events []*zenhub.IssueEvent
+		want   []*pipelineStay
+	}{
+		{
+			name:  "no moves",
+			issue: &github.Issue{CreatedAt: day(1), State: github.StateOpen},
+			want:  []*pipelineStay{},
+		},
+		{
+			name:   "open issue, newest event first",
+			issue:  &github.Issue{CreatedAt: day(1), State: github.StateOpen},
+			events: []*zenhub.IssueEvent{move(5, "In Progress", "Review"), {Type: "estimateIssue", CreatedAt: day(4)}, move(3, "Backlog", "In Progress")},
+			want: []*pipelineStay{
+				{pipeline: "Backlog", entered: day(1), left: day(3), next: "In Progress"},
+				{pipeline: "In Progress", entered: day(3), left: day(5), next: "Review"},
+				{pipeline: "Review", entered: day(5)},
+			},
+		},
+		{
+			name:   "closed issue",
+			issue:  &github.Issue{CreatedAt: day(1), State: github.StateClosed, ClosedAt: &closedAt},
+			events: []*zenhub.IssueEvent{move(3, "Backlog", "In Progress")},
+			want: []*pipelineStay{
+				{pipeline: "Backlog", entered: day(1), left: day(3), next: "In Progress"},
+				{pipeline: "In Progress", entered: day(3), left: day(9), next: closedPipeline},
+				{pipeline: closedPipeline, entered: day(9)},
+			},
+		},
+		{
+			name:   "closed issue moved to the Closed pipeline",
+			issue:  &github.Issue{CreatedAt: day(1), State: github.StateClosed, ClosedAt: &closedAt},
+			events: []*zenhub.IssueEvent{move(9, "Review", "Closed"), move(3, "", "Review")},
+			want: []*pipelineStay{
+				{pipeline: "Review", entered: day(3), left: day(9), next: "Closed"},
+				{pipeline: "Closed", entered: day(9)},
+			},
+		},
+		{
+			name:  "closed issue that was never moved",
+			issue: &github.Issue{CreatedAt: day(1), State: github.StateClosed, ClosedAt: &closedAt},
+			want:  []*pipelineStay{{pipeline: closedPipeline, entered: day(9)}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := pipelineHistory(test.issue, test.events)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("expected %v, got %v", describeHistory(test.want), describeHistory(got))
+			}
+		})
+	}
+}
+
+func TestEnteredAt(t *testing.T) {
+	history := []*pipelineStay{
+		{pipeline: "Backlog", entered: time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{pipeline: "In Progress", entered: time.Date(2018, 1, 3, 0, 0, 0, 0, time.UTC)},
+		{pipeline: "Backlog", entered: time.Date(2018, 1, 4, 0, 0, 0, 0, time.UTC)},
+		{pipeline: "In Progress", entered: time.Date(2018, 1, 5, 0, 0, 0, 0, time.UTC)},
+	}
+	if got := enteredAt(history, "in progress"); !got.Equal(history[1].entered) {
+		t.Errorf("expected the first time the pipeline was entered, got %v", got)
+	}
+	if got := enteredAt(history, "Review"); !got.IsZero() {
+		t.Errorf("expected the zero time for a pipeline that was never entered, got %v", got)
+	}
+}
+
+func describeHistory(history []*pipelineStay) []string {
+	described := []string{}
+	for _, stay := range history {
+		described = append(described, stay.pipeline+" "+stay.entered.Format(dateFormat)+" to "+stay.left.Format(dateFormat)+" -> "+stay.next)
+	}
+	return described
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/eltorocorp/zencli/zen/github"
+	"github.com/eltorocorp/zencli/zen/zenhub"
+)
+
+// CI statuses, summarized from a commit's statuses and check runs.
+const (
+	ciPassing = "CI passing"
+	ciFailing = "CI failing"
+	ciPending = "CI pending"
+	ciNone    = "no CI"
+)
+
+// linkedPullRequest is a pull request that refers to an issue.
+type linkedPullRequest struct {
+	issue *github.Issue
+	// repo is the full name of the pull request's repository.
+	repo string
+	// link is how the pull request refers to the issue: "closes", "connected" or "mentions".
+	link string
+}
+
+// statusWorkers is the most pull request statuses that are fetched at once.
+const statusWorkers = 8
+
+// pullRequestRef is a pull request whose status is to be fetched.
+type pullRequestRef struct {
+	api    *github.API
+	number int
+	status string
+}
+
+// fetchPullRequestStatuses sets the status of each of the pull requests, fetching several of them at once. The first
+// error is returned.
+func fetchPullRequestStatuses(pullRequests []*pullRequestRef) error {
+	work := make(chan *pullRequestRef)
+	errs := make(chan error, len(pullRequests))
+	var wg sync.WaitGroup
+	for i := 0; i < statusWorkers && i < len(pullRequests); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for pullRequest := range work {
+				status, err := pullRequestStatus(pullRequest.api, pullRequest.number)
+				if err != nil {
+					errs <- err
+					continue
+				}
+				pullRequest.status = status
+			}
+		}()
+	}
+	for _, pullRequest := range pullRequests {
+		work <- pullRequest
+	}
+	close(work)
+	wg.Wait()
+	close(errs)
+	return <-errs
+}
+
+// pullRequestStatus returns a summary of the specified pull request's draft flag, CI status and review state, i.e.
+// "draft, CI passing, approved".
+func pullRequestStatus(api *github.API, number int) (string, error) {
+	pullRequest, err := api.GetPullRequest(number)
+	if err != nil {
+		return "", err
+	}
+	ci, err := ciStatus(api, pullRequest.Head.SHA)
+	if err != nil {
+		return "", err
+	}
+	reviews, err := api.GetReviews(number)
+	if err != nil {
+		return "", err
+	}
+
+	status := []string{}
+	switch {
+	case pullRequest.Merged:
+		status = append(status, "merged")
+	case pullRequest.State == github.StateClosed:
+		status = append(status, "closed")
+	case pullRequest.Draft:
+		status = append(status, "draft")
+	}
+	return strings.Join(append(status, ci, reviewState(reviews)), ", "), nil
+}
+
+// ciStatus combines the commit statuses and check runs of a commit into a single status. Any failure fails the
+// commit, and any status or check that has not finished leaves it pending.
+func ciStatus(api *github.API, sha string) (string, error) {
+	combined, err := api.GetCombinedStatus(sha)
+	if err != nil {
+		return "", err
+	}
+	checkRuns, err := api.GetCheckRuns(sha)
+	if err != nil {
+		return "", err
+	}
+
+	failing, pending := false, false
+	if combined.TotalCount > 0 {
+		failing = combined.State == "failure" || combined.State == "error"
+		pending = combined.State == "pending"
+	}
+	for _, checkRun := range checkRuns.List {
+		switch {
+		case checkRun.Status != "completed":
+			pending = true
+		case checkRun.Conclusion == "failure" || checkRun.Conclusion == "timed_out" ||
+			checkRun.Conclusion == "cancelled" || checkRun.Conclusion == "action_required":
+			failing = true
+		}
+	}
+	switch {
+	case failing:
+		return ciFailing, nil
+	case pending:
+		return ciPending, nil
+	case combined.TotalCount == 0 && len(checkRuns.List) == 0:
+		return ciNone, nil
+	}
+	return ciPassing, nil
+}
+
+// reviewState summarizes the reviews of a pull request, using each reviewer's latest approval or request for
+// changes. A request for changes outweighs any approvals.
+func reviewState(reviews []*github.Review) string {
+	latest := map[string]string{}
+	for _, review := range reviews {
+		switch review.State {
+		case github.ReviewApproved, github.ReviewChangesRequested, github.ReviewDismissed:
+			latest[review.User.Login] = review.State
+		}
+	}
+	approved := false
+	for _, state := range latest {
+		if state == github.ReviewChangesRequested {
+			return "changes requested"
+		}
+		approved = approved || state == github.ReviewApproved
+	}
+	if approved {
+		return "approved"
+	}
+	return "awaiting review"
+}
+
+// linkedPullRequests returns the pull requests that are linked to the specified issue: those connected to it in
+// ZenHub, followed by any others that refer to it, from the cross-references in its timeline. The ZenHub connections
+// need a GraphQL key; without one, only the cross-references are used (which include "connects #123" references).
+func (a *Actions) linkedPullRequests(issue int) ([]*linkedPullRequest, error) {
+	connections, err := a.zenHubAPI.GetConnectedPullRequests(issue)
+	if err != nil && err != zenhub.ErrNoGraphQLKey {
+		return nil, err
+	}
+	linked := []*linkedPullRequest{}
+	found := map[string]bool{}
+	for _, connection := range connections {
+		owner, repo := splitFullName(connection.Repo)
+		actions, err := a.ForRepo(owner, repo)
+		if err != nil {
+			return nil, err
+		}
+		pullRequest, err := actions.(*Actions).githubAPI.GetIssue(connection.Number)
+		if err != nil {
+			return nil, err
+		}
+		found[pullRequestKey(connection.Repo, connection.Number)] = true
+		linked = append(linked, &linkedPullRequest{issue: pullRequest, repo: connection.Repo, link: "connected"})
+	}
+
+	events, err := a.githubAPI.GetTimeline(issue)
+	if err != nil {
+		return nil, err
+	}
+	for _, reference := range referencingPullRequests(events, a.githubAPI.Owner(), a.githubAPI.RepoName, issue) {
+		if !found[pullRequestKey(reference.repo, reference.issue.Number)] {
+			linked = append(linked, reference)
+		}
+	}
+	return linked, nil
+}
+
+func pullRequestKey(repo string, number int) string {
+	return fmt.Sprintf("%v#%v", strings.ToLower(repo), number)
+}
+
+// connectsPattern and closesPattern match a keyword that connects or closes an issue, and the reference to the
+// issue that follows it: "#123", "repo#123" or "owner/repo#123".
+var (
+	connectsPattern = regexp.MustCompile(`(?i)\bconnect(?:s|ed)?(?:\s+to)?:?\s+((?:[\w.-]+/)?[\w.-]+)?#([0-9]+)\b`)
+	closesPattern   = regexp.MustCompile(`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?):?\s+((?:[\w.-]+/)?[\w.-]+)?#([0-9]+)\b`)
+)
+
+// referencingPullRequests returns the pull requests that refer to the specified issue, from the cross-references
+// in its timeline. A pull request whose body connects the issue ("connects #123") or closes it ("fixes #123") is
+// linked as such; any other reference is a mention. This finds links that ZenHub does not know about.
+func referencingPullRequests(events []*github.TimelineEvent, owner, repo string, issue int) []*linkedPullRequest {
+	fullName := owner + "/" + repo
+	linked := []*linkedPullRequest{}
+	found := map[string]bool{}
+	for _, event := range events {
+		if event.Event != "cross-referenced" || event.Source == nil || event.Source.Issue == nil {
+			continue
+		}
+		source := event.Source.Issue
+		if !source.IsPullRequest() {
+			continue
+		}
+		sourceRepo := fullName
+		if source.Repository != nil && source.Repository.FullName != "" {
+			sourceRepo = source.Repository.FullName
+		}
+		key := pullRequestKey(sourceRepo, source.Number)
+		if found[key] {
+			continue
+		}
+		found[key] = true
+
+		local := strings.EqualFold(sourceRepo, fullName)
+		link := "mentions"
+		switch {
+		case refersTo(connectsPattern, source.Body, owner, repo, issue, local):
+			link = "connected"
+		case refersTo(closesPattern, source.Body, owner, repo, issue, local):
+			link = "closes"
+		}
+		linked = append(linked, &linkedPullRequest{issue: source, repo: sourceRepo, link: link})
+	}
+	return linked
+}
+
+// refersTo returns true if the pattern matches a reference to the specified issue in the body. A reference without
+// the owner ("#123" or "repo#123") only refers to the issue from a pull request in the same repository.
+func refersTo(pattern *regexp.Regexp, body, owner, repo string, issue int, local bool) bool {
+	for _, match := range pattern.FindAllStringSubmatch(body, -1) {
+		if match[2] != strconv.Itoa(issue) {
+			continue
+		}
+		reference := match[1]
+		switch {
+		case strings.EqualFold(reference, owner+"/"+repo):
+			return true
+		case local && (reference == "" || strings.EqualFold(reference, repo)):
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/eltorocorp/zencli/zen/github"
+)
+
+func TestReferencingPullRequests(t *testing.T) {
+	crossReference := func(repo string, number int, body string, isPullRequest bool) *github.TimelineEvent {
+		issue := &github.Issue{Number: number, Body: body, Repository: &github.Repository{FullName: repo}}
+		if isPullRequest {
+			issue.PullRequest = &github.IssuePullRequest{}
+		}
+		return &github.TimelineEvent{Event: "cross-referenced", Source: &github.TimelineSource{Issue: issue}}
+	}
+
+	tests := []struct {
+		name  string
+		event *github.TimelineEvent
+		link  string
+	}{
+		{"closes", crossReference("eltorocorp/zencli", 10, "Fixes #12", true), "closes"},
+		{"closes with repo", crossReference("eltorocorp/zencli", 10, "resolves zencli#12", true), "closes"},
+		{"connects", crossReference("eltorocorp/zencli", 10, "Connects to #12", true), "connected"},
+		{"connects another issue", crossReference("eltorocorp/zencli", 10, "connects #123, mentions #12", true), "mentions"},
+		{"closes another issue", crossReference("eltorocorp/zencli", 10, "closes #1 and #12", true), "mentions"},
+		{"remote with full name", crossReference("eltorocorp/api", 3, "closes eltorocorp/zencli#12", true), "closes"},
+		{"remote without owner", crossReference("eltorocorp/api", 3, "closes #12", true), "mentions"},
+		{"remote with repo only", crossReference("eltorocorp/api", 3, "closes zencli#12", true), "mentions"},
+		{"other owner", crossReference("eltorocorp/api", 3, "closes someone/zencli#12", true), "mentions"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			linked := referencingPullRequests([]*github.TimelineEvent{test.event}, "eltorocorp", "zencli", 12)
+			if len(linked) != 1 {
+				t.Fatalf("expected 1 linked pull request, got %v", len(linked))
+			}
+			if linked[0].link != test.link {
+				t.Errorf("expected the link to be %q, got %q", test.link, linked[0].link)
+			}
+		})
+	}
+
+	t.Run("skips issues and duplicates", func(t *testing.T) {
+		events := []*github.TimelineEvent{
+			crossReference("eltorocorp/zencli", 10, "closes #12", true),
+			crossReference("eltorocorp/zencli", 10, "closes #12", true),
+			crossReference("eltorocorp/zencli", 11, "see #12", false),
+			{Event: "labeled"},
+		}
+		linked := referencingPullRequests(events, "eltorocorp", "zencli", 12)
+		if len(linked) != 1 || linked[0].issue.Number != 10 {
+			t.Errorf("expected only pull request 10, got %v", linked)
+		}
+	})
+}
+
+func TestReviewState(t *testing.T) {
+	review := func(login, state string) *github.Review {
+		return &github.Review{User: github.User{Login: login}, State: state}
+	}
+	tests := []struct {
+		name    string
+		reviews []*github.Review
+		want    string
+	}{
+		{"no reviews", nil, "awaiting review"},
+		{"only comments", []*github.Review{review("a", github.ReviewCommented)}, "awaiting review"},
+		{"approved", []*github.Review{review("a", github.ReviewApproved), review("a", github.ReviewCommented)}, "approved"},
+		{"changes requested", []*github.Review{review("a", github.ReviewApproved), review("b", github.ReviewChangesRequested)}, "changes requested"},
+		{"changes addressed", []*github.Review{review("b", github.ReviewChangesRequested), review("b", github.ReviewApproved)}, "approved"},
+		{"dismissed", []*github.Review{review("a", github.ReviewApproved), review("a", github.ReviewDismissed)}, "awaiting review"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := reviewState(test.reviews); got != test.want {
+				t.Errorf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+}
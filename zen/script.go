@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/eltorocorp/zencli/zen/command"
+	"github.com/eltorocorp/zencli/zen/shellwords"
+)
+
+var assignmentPattern = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)=(.*)$`)
+
+// script holds the state of a script that is being run by Actions.Run.
+type script struct {
+	variables   map[string]string
+	exitOnError bool
+	dryRun      bool
+}
+
+func (s *script) lookup(name string) (string, bool) {
+	if value, ok := s.variables[name]; ok {
+		return value, true
+	}
+	return os.LookupEnv(name)
+}
+
+// run runs a single line of the script, which is either a variable assignment, a "set" option, or a zen command.
+func (s *script) run(a *Actions, line string) error {
+	expanded, err := shellwords.Expand(line, s.lookup)
+	if err != nil {
+		return err
+	}
+	args, err := shellwords.Split(expanded)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return nil
+	}
+
+	if match := assignmentPattern.FindStringSubmatch(args[0]); match != nil && len(args) == 1 {
+		s.variables[match[1]] = match[2]
+		return nil
+	}
+
+	switch args[0] {
+	case "set":
+		if len(args) != 2 || (args[1] != "-e" && args[1] != "+e") {
+			return errors.New("only \"set -e\" and \"set +e\" are supported")
+		}
+		s.exitOnError = args[1] == "-e"
+		return nil
+	case "run", "shell":
+		return fmt.Errorf("'%v' cannot be used in a script", args[0])
+	}
+
+	if s.dryRun {
+		fmt.Println("$ zen " + strings.Join(quoteAll(args), " "))
+	}
+	return command.New(append([]string{"zen"}, args...), a).Execute()
+}
+
+func quoteAll(args []string) []string {
+	quoted := []string{}
+	for _, arg := range args {
+		quoted = append(quoted, shellwords.Quote(arg))
+	}
+	return quoted
+}
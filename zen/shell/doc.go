@@ -0,0 +1,2 @@
+// Package shell provides the pieces of zen's interactive shell: a line reader with editing, history and tab completion. Lines are split into arguments with the shellwords package.
+package shell
@@ -0,0 +1,350 @@
+package shell
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/eltorocorp/zencli/zen/shellwords"
+)
+
+// HistoryLimit is the number of lines that are kept in the history.
+const HistoryLimit = 1000
+
+// ErrInterrupted is returned by ReadLine when the line is abandoned with Ctrl-C.
+var ErrInterrupted = errors.New("interrupted")
+
+// Completer returns the completions for the current (partially typed) word, given the complete words before it.
+type Completer func(args []string, current string) []string
+
+// Reader reads lines from the terminal with line editing, history and tab completion. If stdin is not a terminal,
+// lines are read as is, without a prompt.
+type Reader struct {
+	in          *bufio.Reader
+	out         io.Writer
+	terminal    bool
+	history     []string
+	historyPath string
+	complete    Completer
+}
+
+// NewReader returns a reference to a reader that persists its history to the specified file (if it is not
+// empty), and completes words with the supplied completer.
+func NewReader(historyPath string, complete Completer) *Reader {
+	r := &Reader{
+		in:          bufio.NewReader(os.Stdin),
+		out:         os.Stdout,
+		terminal:    isTerminal(),
+		historyPath: historyPath,
+		complete:    complete,
+	}
+	r.loadHistory()
+	return r
+}
+
+// ReadLine displays the prompt and reads a line. io.EOF is returned when the input ends (or Ctrl-D is pressed on
+// an empty line), and ErrInterrupted is returned when Ctrl-C is pressed.
+func (r *Reader) ReadLine(prompt string) (string, error) {
+	if !r.terminal {
+		return r.readPlainLine()
+	}
+	restore, err := makeRaw()
+	if err != nil {
+		fmt.Fprint(r.out, prompt)
+		return r.readPlainLine()
+	}
+	defer restore()
+
+	line, err := r.edit(prompt)
+	if err == nil {
+		r.addHistory(line)
+	}
+	return line, err
+}
+
+func (r *Reader) readPlainLine() (string, error) {
+	line, err := r.in.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	return strings.TrimRight(line, "\r\n"), err
+}
+
+// editor holds the state of the line being edited.
+type editor struct {
+	reader  *Reader
+	prompt  string
+	buffer  []rune
+	cursor  int
+	index   int
+	current []rune
+}
+
+func (r *Reader) edit(prompt string) (string, error) {
+	e := &editor{reader: r, prompt: prompt, index: len(r.history)}
+	e.refresh()
+	for {
+		key, _, err := r.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		switch key {
+		case '\r', '\n':
+			fmt.Fprint(r.out, "\r\n")
+			return string(e.buffer), nil
+		case 3: // Ctrl-C
+			fmt.Fprint(r.out, "^C\r\n")
+			return "", ErrInterrupted
+		case 4: // Ctrl-D
+			if len(e.buffer) == 0 {
+				fmt.Fprint(r.out, "\r\n")
+				return "", io.EOF
+			}
+			e.delete(e.cursor, e.cursor+1)
+		case 1: // Ctrl-A
+			e.cursor = 0
+		case 5: // Ctrl-E
+			e.cursor = len(e.buffer)
+		case 2: // Ctrl-B
+			e.move(-1)
+		case 6: // Ctrl-F
+			e.move(1)
+		case 11: // Ctrl-K
+			e.delete(e.cursor, len(e.buffer))
+		case 21: // Ctrl-U
+			e.delete(0, e.cursor)
+		case 23: // Ctrl-W
+			e.delete(e.previousWord(), e.cursor)
+		case 12: // Ctrl-L
+			fmt.Fprint(r.out, "\x1b[H\x1b[2J")
+		case 16: // Ctrl-P
+			e.recall(-1)
+		case 14: // Ctrl-N
+			e.recall(1)
+		case 127, 8: // Backspace
+			if e.cursor > 0 {
+				e.delete(e.cursor-1, e.cursor)
+			}
+		case '\t':
+			e.completeWord()
+		case 27:
+			e.escape()
+		default:
+			if key >= ' ' {
+				e.insert([]rune{key})
+			}
+		}
+		e.refresh()
+	}
+}
+
+// escape handles the escape sequences sent by the arrow, home, end and delete keys.
+func (e *editor) escape() {
+	next, _, err := e.reader.in.ReadRune()
+	if err != nil || (next != '[' && next != 'O') {
+		return
+	}
+	sequence := ""
+	for {
+		r, _, err := e.reader.in.ReadRune()
+		if err != nil {
+			return
+		}
+		sequence += string(r)
+		if (r >= 'A' && r <= 'Z') || r == '~' {
+			break
+		}
+	}
+	switch sequence {
+	case "A":
+		e.recall(-1)
+	case "B":
+		e.recall(1)
+	case "C":
+		e.move(1)
+	case "D":
+		e.move(-1)
+	case "H", "1~":
+		e.cursor = 0
+	case "F", "4~":
+		e.cursor = len(e.buffer)
+	case "3~":
+		e.delete(e.cursor, e.cursor+1)
+	}
+}
+
+func (e *editor) refresh() {
+	fmt.Fprintf(e.reader.out, "\r%v%v\x1b[K", e.prompt, string(e.buffer))
+	if back := len(e.buffer) - e.cursor; back > 0 {
+		fmt.Fprintf(e.reader.out, "\x1b[%vD", back)
+	}
+}
+
+func (e *editor) move(offset int) {
+	e.cursor += offset
+	if e.cursor < 0 {
+		e.cursor = 0
+	}
+	if e.cursor > len(e.buffer) {
+		e.cursor = len(e.buffer)
+	}
+}
+
+func (e *editor) insert(text []rune) {
+	buffer := append([]rune{}, e.buffer[:e.cursor]...)
+	buffer = append(buffer, text...)
+	e.buffer = append(buffer, e.buffer[e.cursor:]...)
+	e.cursor += len(text)
+}
+
+func (e *editor) delete(from, to int) {
+	if to > len(e.buffer) {
+		to = len(e.buffer)
+	}
+	if from >= to {
+		return
+	}
+	e.buffer = append(e.buffer[:from], e.buffer[to:]...)
+	e.cursor = from
+}
+
+// previousWord returns the position of the start of the word before the cursor.
+func (e *editor) previousWord() int {
+	i := e.cursor
+	for i > 0 && e.buffer[i-1] == ' ' {
+		i--
+	}
+	for i > 0 && e.buffer[i-1] != ' ' {
+		i--
+	}
+	return i
+}
+
+// recall replaces the buffer with an earlier (offset -1) or later (offset 1) line from the history. The line being
+// edited is kept, so that moving past the end of the history restores it.
+func (e *editor) recall(offset int) {
+	history := e.reader.history
+	index := e.index + offset
+	if index < 0 || index > len(history) {
+		return
+	}
+	if e.index == len(history) {
+		e.current = append([]rune{}, e.buffer...)
+	}
+	e.index = index
+	if index == len(history) {
+		e.buffer = append([]rune{}, e.current...)
+	} else {
+		e.buffer = []rune(history[index])
+	}
+	e.cursor = len(e.buffer)
+}
+
+// completeWord completes the word before the cursor. A single completion replaces the word; if there are several,
+// the word is extended to their longest common prefix, or they are listed below the prompt if it cannot be extended.
+func (e *editor) completeWord() {
+	if e.reader.complete == nil {
+		return
+	}
+	words, _, partial := shellwords.Words(string(e.buffer[:e.cursor]))
+	args := []string{}
+	for _, w := range words {
+		args = append(args, w.Text)
+	}
+	current, start := "", e.cursor
+	if partial {
+		last := words[len(words)-1]
+		current, start, args = last.Text, last.Start, args[:len(args)-1]
+	}
+
+	candidates := e.reader.complete(args, current)
+	switch {
+	case len(candidates) == 0:
+		fmt.Fprint(e.reader.out, "\a")
+		return
+	case len(candidates) == 1:
+		replacement := shellwords.Quote(candidates[0])
+		if !strings.HasSuffix(candidates[0], ",") {
+			replacement += " "
+		}
+		e.replace(start, replacement)
+		return
+	}
+
+	prefix := commonPrefix(candidates)
+	if len([]rune(prefix)) > len([]rune(current)) {
+		e.replace(start, shellwords.Quote(prefix))
+		return
+	}
+	fmt.Fprint(e.reader.out, "\r\n"+strings.Join(candidates, "    ")+"\r\n")
+}
+
+func (e *editor) replace(start int, text string) {
+	e.delete(start, e.cursor)
+	e.cursor = start
+	e.insert([]rune(text))
+}
+
+func commonPrefix(values []string) string {
+	prefix := []rune(values[0])
+	for _, value := range values[1:] {
+		runes := []rune(value)
+		i := 0
+		for i < len(prefix) && i < len(runes) && prefix[i] == runes[i] {
+			i++
+		}
+		prefix = prefix[:i]
+	}
+	return string(prefix)
+}
+
+// loadHistory reads the most recent lines of the history from the history file. If the file has grown well past
+// the limit, it is rewritten with only those lines.
+func (r *Reader) loadHistory() {
+	if r.historyPath == "" {
+		return
+	}
+	content, err := ioutil.ReadFile(r.historyPath)
+	if err != nil {
+		return
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		if line != "" {
+			r.history = append(r.history, line)
+		}
+	}
+	if len(r.history) > HistoryLimit {
+		rewrite := len(r.history) > 2*HistoryLimit
+		r.history = r.history[len(r.history)-HistoryLimit:]
+		if rewrite {
+			ioutil.WriteFile(r.historyPath, []byte(strings.Join(r.history, "\n")+"\n"), 0600)
+		}
+	}
+}
+
+// addHistory adds the line to the history, unless it is empty or the same as the previous line. Failures to write
+// the history file are ignored, since the history is only a convenience.
+func (r *Reader) addHistory(line string) {
+	line = strings.TrimSpace(line)
+	if line == "" || (len(r.history) > 0 && r.history[len(r.history)-1] == line) {
+		return
+	}
+	r.history = append(r.history, line)
+	if r.historyPath == "" {
+		return
+	}
+	if os.MkdirAll(filepath.Dir(r.historyPath), 0700) != nil {
+		return
+	}
+	file, err := os.OpenFile(r.historyPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	fmt.Fprintln(file, line)
+}
@@ -0,0 +1,41 @@
+package shell
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// isTerminal returns true if both stdin and stdout are terminals.
+func isTerminal() bool {
+	for _, file := range []*os.File{os.Stdin, os.Stdout} {
+		info, err := file.Stat()
+		if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// makeRaw puts the terminal into raw mode, so that keys can be read one at a time without being echoed, and returns
+// a function that restores the previous mode. stty is used so that no platform specific system calls are needed.
+func makeRaw() (func(), error) {
+	state, err := stty("-g")
+	if err != nil {
+		return nil, err
+	}
+	_, err = stty("raw", "-echo")
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		stty(strings.TrimSpace(state))
+	}, nil
+}
+
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	output, err := cmd.Output()
+	return string(output), err
+}
@@ -0,0 +1,2 @@
+// Package shellwords splits lines into arguments, quotes arguments and expands variables the way a POSIX shell would. It is shared by zen's interactive shell, scripts and aliases.
+package shellwords
@@ -0,0 +1,89 @@
+package shellwords
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Expand replaces the variable references ($NAME or ${NAME}) in a line with their values, which are looked up with
+// the supplied function. As in a POSIX shell, variables are not expanded within single quotes. A value is quoted so
+// that Split returns it as a single argument (or as part of the quoted argument that it appears in), even if it
+// contains whitespace. A reference to a variable that is not defined is an error.
+func Expand(line string, lookup func(name string) (string, bool)) (string, error) {
+	runes := []rune(line)
+	b := &strings.Builder{}
+	quote := rune(0)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\\' && quote != '\'' && i+1 < len(runes):
+			b.WriteRune(r)
+			b.WriteRune(runes[i+1])
+			i++
+			continue
+		case (r == '\'' || r == '"') && quote == 0:
+			quote = r
+		case r == quote:
+			quote = 0
+		}
+		if r != '$' || quote == '\'' {
+			b.WriteRune(r)
+			continue
+		}
+
+		name, end := variableName(runes, i+1)
+		if name == "" {
+			b.WriteRune(r)
+			continue
+		}
+		value, ok := lookup(name)
+		if !ok {
+			return "", fmt.Errorf("the variable '%v' is not defined", name)
+		}
+		if quote == '"' {
+			b.WriteString(strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value))
+		} else {
+			b.WriteString(Quote(value))
+		}
+		i = end - 1
+	}
+	return b.String(), nil
+}
+
+// variableName returns the name of the variable referred to at the start position (just after the "$"), and the
+// position after the end of the reference. The name is empty if there is no valid reference.
+func variableName(runes []rune, start int) (string, int) {
+	if start < len(runes) && runes[start] == '{' {
+		for end := start + 1; end < len(runes); end++ {
+			if runes[end] == '}' {
+				name := string(runes[start+1 : end])
+				if !isName(name) {
+					return "", start
+				}
+				return name, end + 1
+			}
+		}
+		return "", start
+	}
+	end := start
+	for end < len(runes) && isNameRune(runes[end], end == start) {
+		end++
+	}
+	return string(runes[start:end]), end
+}
+
+func isName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		if !isNameRune(r, i == 0) {
+			return false
+		}
+	}
+	return true
+}
+
+func isNameRune(r rune, first bool) bool {
+	return r == '_' || (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z') || (!first && r >= '0' && r <= '9')
+}
@@ -0,0 +1,61 @@
+package shellwords
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	variables := map[string]string{
+		"SPRINT":   "Sprint 12",
+		"ISSUE":    "123",
+		"QUOTED":   `say "hi"`,
+		"_under_1": "x",
+	}
+	lookup := func(name string) (string, bool) {
+		value, ok := variables[name]
+		return value, ok
+	}
+
+	tests := []struct {
+		line  string
+		want  string
+		split []string
+	}{
+		{"show $ISSUE", "show 123", []string{"show", "123"}},
+		{"show ${ISSUE}0", "show 1230", []string{"show", "1230"}},
+		{"milestone 1 $SPRINT", `milestone 1 "Sprint 12"`, []string{"milestone", "1", "Sprint 12"}},
+		{`comment 1 "In $SPRINT: $QUOTED"`, `comment 1 "In Sprint 12: say \"hi\""`, []string{"comment", "1", `In Sprint 12: say "hi"`}},
+		{"comment 1 '$SPRINT'", "comment 1 '$SPRINT'", []string{"comment", "1", "$SPRINT"}},
+		{`comment 1 \$SPRINT`, `comment 1 \$SPRINT`, []string{"comment", "1", "$SPRINT"}},
+		{"$_under_1$ISSUE", "x123", []string{"x123"}},
+		{"cost $5 and $ and ${}", "cost $5 and $ and ${}", []string{"cost", "$5", "and", "$", "and", "${}"}},
+	}
+	for _, test := range tests {
+		t.Run(test.line, func(t *testing.T) {
+			got, err := Expand(test.line, lookup)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != test.want {
+				t.Errorf("expected %v, got %v", test.want, got)
+			}
+			split, err := Split(got)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(split, test.split) {
+				t.Errorf("expected %q, got %q", test.split, split)
+			}
+		})
+	}
+
+	for _, line := range []string{"show $MISSING", "show ${MISSING}", `show "$MISSING"`} {
+		t.Run(line, func(t *testing.T) {
+			_, err := Expand(line, lookup)
+			if err == nil || err.Error() != "the variable 'MISSING' is not defined" {
+				t.Errorf("expected the variable to be undefined, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package shellwords
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrUnterminatedQuote is returned by Split when a line ends inside a quoted string.
+var ErrUnterminatedQuote = errors.New("the line ends inside a quoted string")
+
+// Word is a single word from a line.
+type Word struct {
+	Text string
+	// Start is the offset (in runes) at which the word starts in the line.
+	Start int
+	// Quoted is true if any part of the word was quoted or escaped, so that (for example) a quoted "&&" can be told
+	// apart from the operator.
+	Quoted bool
+}
+
+// Split splits a line into arguments the way a POSIX shell would. Arguments are separated by whitespace, and
+// whitespace can be included in an argument by quoting it with single or double quotes, or escaping it with a
+// backslash. Within double quotes, a backslash only escapes a double quote or another backslash.
+func Split(line string) ([]string, error) {
+	words, open, _ := Words(line)
+	if open {
+		return nil, ErrUnterminatedQuote
+	}
+	args := []string{}
+	for _, w := range words {
+		args = append(args, w.Text)
+	}
+	return args, nil
+}
+
+// Words splits a line into words in the same way as Split. open is true if the line ends inside a quoted string (or
+// after a backslash), and partial is true if the line ends inside a word, rather than with whitespace.
+func Words(line string) (words []Word, open bool, partial bool) {
+	runes := []rune(line)
+	b := &strings.Builder{}
+	inWord := false
+	start := 0
+	quoted := false
+	quote := rune(0)
+	escaped := false
+	for i, r := range runes {
+		switch {
+		case escaped:
+			b.WriteRune(r)
+			escaped = false
+		case quote == '\'' && r == '\'':
+			quote = 0
+		case quote == '\'':
+			b.WriteRune(r)
+		case quote == '"' && r == '"':
+			quote = 0
+		case quote == '"' && r == '\\' && i+1 < len(runes) && (runes[i+1] == '"' || runes[i+1] == '\\'):
+			escaped = true
+		case quote == '"':
+			b.WriteRune(r)
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, Word{Text: b.String(), Start: start, Quoted: quoted})
+				b.Reset()
+				inWord = false
+			}
+			continue
+		case r == '\\':
+			escaped = true
+			quoted = true
+		case r == '\'' || r == '"':
+			quote = r
+			quoted = true
+		default:
+			b.WriteRune(r)
+		}
+		if !inWord {
+			inWord = true
+			start = i
+			quoted = quote != 0 || escaped
+		}
+	}
+	if inWord {
+		words = append(words, Word{Text: b.String(), Start: start, Quoted: quoted})
+	}
+	return words, quote != 0 || escaped, inWord
+}
+
+// Quote quotes an argument so that Split returns it unchanged, if it contains whitespace, quotes or backslashes. An
+// argument containing "&" is quoted too, so that "&&" is not taken as the operator that chains commands.
+func Quote(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, " \t\n'\"\\&") {
+		return arg
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(arg) + `"`
+}
@@ -0,0 +1,90 @@
+package shellwords
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"", []string{}},
+		{"   ", []string{}},
+		{"move 12 to review", []string{"move", "12", "to", "review"}},
+		{"  move\t12 \n review ", []string{"move", "12", "review"}},
+		{`comment 12 "Deployed to production."`, []string{"comment", "12", "Deployed to production."}},
+		{`comment 12 'it"s'`, []string{"comment", "12", `it"s`}},
+		{`a "it's" b`, []string{"a", "it's", "b"}},
+		{`a\ b`, []string{"a b"}},
+		{`"a \"b\" \\ \c"`, []string{`a "b" \ \c`}},
+		{`'a \b'`, []string{`a \b`}},
+		{`a"b c"d`, []string{"ab cd"}},
+		{`"" ''`, []string{"", ""}},
+	}
+	for _, test := range tests {
+		t.Run(test.line, func(t *testing.T) {
+			got, err := Split(test.line)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+
+	for _, line := range []string{`"abc`, `'abc`, `abc\`, `"abc\"`} {
+		t.Run(line, func(t *testing.T) {
+			if _, err := Split(line); err != ErrUnterminatedQuote {
+				t.Errorf("expected %v, got %v", ErrUnterminatedQuote, err)
+			}
+		})
+	}
+}
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		line    string
+		want    []Word
+		partial bool
+	}{
+		{"", nil, false},
+		{"show 12", []Word{{Text: "show", Start: 0}, {Text: "12", Start: 5}}, true},
+		{"show 12 ", []Word{{Text: "show", Start: 0}, {Text: "12", Start: 5}}, false},
+		{`a && "&&" \&& b'&&'`, []Word{{Text: "a"}, {Text: "&&", Start: 2}, {Text: "&&", Start: 5, Quoted: true}, {Text: "&&", Start: 10, Quoted: true}, {Text: "b&&", Start: 14, Quoted: true}}, true},
+		{`é "x`, []Word{{Text: "é"}, {Text: "x", Start: 2, Quoted: true}}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.line, func(t *testing.T) {
+			got, _, partial := Words(test.line)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("expected %+v, got %+v", test.want, got)
+			}
+			if partial != test.partial {
+				t.Errorf("expected partial to be %v, got %v", test.partial, partial)
+			}
+		})
+	}
+}
+
+func TestQuote(t *testing.T) {
+	tests := map[string]string{
+		"review":      "review",
+		"":            `""`,
+		"in progress": `"in progress"`,
+		`it's`:        `"it's"`,
+		`a "b" \ c`:   `"a \"b\" \\ c"`,
+		"&&":          `"&&"`,
+	}
+	for arg, want := range tests {
+		got := Quote(arg)
+		if got != want {
+			t.Errorf("Quote(%q): expected %v, got %v", arg, want, got)
+		}
+		if split, err := Split(got); err != nil || len(split) != 1 || split[0] != arg {
+			t.Errorf("expected %v to split back into %q, got %q (%v)", got, arg, split, err)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eltorocorp/zencli/zen/github"
+	"github.com/eltorocorp/zencli/zen/zenhub"
+)
+
+// Standup groups.
+const (
+	standupDone       = "Done"
+	standupInProgress = "In progress"
+	standupBlocked    = "Blocked"
+)
+
+// standupItem is an issue or pull request that the user did something to, with what they did.
+type standupItem struct {
+	issue      *github.Issue
+	activities []string
+	// pipeline is the pipeline that the user last moved the issue to, if they moved it.
+	pipeline string
+}
+
+// standupReport collects the items for a standup, in the order that they were first found.
+type standupReport struct {
+	items map[int]*standupItem
+	order []int
+}
+
+func (r *standupReport) add(issue *github.Issue, activity string) {
+	item, ok := r.items[issue.Number]
+	if !ok {
+		item = &standupItem{issue: issue}
+		r.items[issue.Number] = item
+		r.order = append(r.order, issue.Number)
+	}
+	for _, existing := range item.activities {
+		if existing == activity {
+			return
+		}
+	}
+	item.activities = append(item.activities, activity)
+}
+
+// Standup prints what the user (or the specified login) did since the specified time (i.e. 1d): the issues that
+// they opened, closed, commented on, were assigned or moved between pipelines, and the pull requests that they
+// opened or reviewed. The items are grouped into done, in progress and blocked, as text or Markdown.
+func (a *Actions) Standup(since, login, output string) error {
+	if since == "" {
+		since = "1d"
+	}
+	sinceTime, err := parseSince(since, time.Now())
+	if err != nil {
+		return err
+	}
+	var user *github.User
+	if login == "" || login == "me" {
+		user, err = a.githubAPI.GetAuthenticatedUser()
+	} else {
+		user, err = a.githubAPI.GetUser(login)
+	}
+	if err != nil {
+		return err
+	}
+
+	report := &standupReport{items: map[int]*standupItem{}}
+	err = a.addStandupActivity(report, user, sinceTime)
+	if err != nil {
+		return err
+	}
+	groups, err := a.groupStandupItems(report)
+	if err != nil {
+		return err
+	}
+	printStandup(user.Login, sinceTime, groups, output == "markdown")
+	return nil
+}
+
+// addStandupActivity finds what the user did since the specified time, from the github events, comments and
+// searches, and from the ZenHub events of the issues that they touched or are assigned to.
+func (a *Actions) addStandupActivity(report *standupReport, user *github.User, since time.Time) error {
+	events, err := a.githubAPI.GetIssueEvents(since)
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		switch {
+		case event.Issue == nil:
+		case event.Event == "assigned" && event.Assignee != nil && event.Assignee.Login == user.Login:
+			report.add(event.Issue, "assigned")
+		case event.Actor.Login != user.Login:
+		case event.Event == "closed" || event.Event == "reopened" || event.Event == "merged":
+			report.add(event.Issue, event.Event)
+		}
+	}
+
+	date := since.UTC().Format(dateFormat)
+	opened, err := a.githubAPI.SearchIssues(fmt.Sprintf("author:%v created:>=%v", user.Login, date))
+	if err != nil {
+		return err
+	}
+	for _, issue := range opened {
+		if issue.CreatedAt.Before(since) {
+			continue
+		}
+		if issue.IsPullRequest() {
+			report.add(issue, "opened PR")
+		} else {
+			report.add(issue, "opened")
+		}
+	}
+
+	reviewed, err := a.githubAPI.SearchIssues(fmt.Sprintf("is:pr reviewed-by:%v updated:>=%v", user.Login, date))
+	if err != nil {
+		return err
+	}
+	for _, pullRequest := range reviewed {
+		reviews, err := a.githubAPI.GetReviews(pullRequest.Number)
+		if err != nil {
+			return err
+		}
+		for _, review := range reviews {
+			if review.User.Login == user.Login && !review.SubmittedAt.Before(since) {
+				report.add(pullRequest, "reviewed ("+strings.ToLower(strings.Replace(review.State, "_", " ", -1))+")")
+			}
+		}
+	}
+
+	comments, err := a.githubAPI.GetRepoComments(since)
+	if err != nil {
+		return err
+	}
+	for _, comment := range comments {
+		if comment.User.Login != user.Login || comment.CreatedAt.Before(since) {
+			continue
+		}
+		number, err := strconv.Atoi(comment.IssueURL[strings.LastIndex(comment.IssueURL, "/")+1:])
+		if err != nil {
+			continue
+		}
+		issue, err := a.standupIssue(report, number)
+		if err != nil {
+			return err
+		}
+		report.add(issue, "commented")
+	}
+
+	// Moves between pipelines are only recorded by ZenHub, per issue, so check the issues that are already in the
+	// report and the open issues assigned to the user.
+	assigned, err := a.githubAPI.SearchIssues(fmt.Sprintf("is:open assignee:%v", user.Login))
+	if err != nil {
+		return err
+	}
+	candidates := map[int]*github.Issue{}
+	for _, number := range report.order {
+		candidates[number] = report.items[number].issue
+	}
+	for _, issue := range assigned {
+		candidates[issue.Number] = issue
+	}
+	numbers := []int{}
+	for number := range candidates {
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+	for _, number := range numbers {
+		zenHubEvents, err := a.zenHubAPI.GetIssueEvents(number)
+		if err != nil {
+			return err
+		}
+		report.addTransfers(candidates[number], zenHubEvents, user.ID, since)
+	}
+	return nil
+}
+
+// addTransfers adds the moves between pipelines that the user (by github ID) made to the issue since the specified
+// time, from the issue's ZenHub events (newest first), and records the pipeline that the user last moved it to.
+func (r *standupReport) addTransfers(issue *github.Issue, events []*zenhub.IssueEvent, userID int, since time.Time) {
+	for i := len(events) - 1; i >= 0; i-- {
+		event := events[i]
+		if event.Type != "transferIssue" || event.UserID != userID || event.CreatedAt.Before(since) || event.ToPipeline == nil {
+			continue
+		}
+		activity := "moved to " + event.ToPipeline.Name
+		if event.FromPipeline != nil {
+			activity = fmt.Sprintf("moved from %v to %v", event.FromPipeline.Name, event.ToPipeline.Name)
+		}
+		r.add(issue, activity)
+		r.items[issue.Number].pipeline = event.ToPipeline.Name
+	}
+}
+
+// standupIssue returns the issue from the report if it is already there, and otherwise fetches it.
+func (a *Actions) standupIssue(report *standupReport, number int) (*github.Issue, error) {
+	if item, ok := report.items[number]; ok {
+		return item.issue, nil
+	}
+	return a.githubAPI.GetIssue(number)
+}
+
+// groupStandupItems sorts the items into done, in progress and blocked, using the pipelines on the board.
+func (a *Actions) groupStandupItems(report *standupReport) (map[string][]*standupItem, error) {
+	pipelines, err := a.zenHubAPI.GetPipelines()
+	if err != nil {
+		return nil, err
+	}
+	issuePipelines := map[int]string{}
+	for _, pipeline := range pipelines.List {
+		for _, issue := range pipeline.Issues {
+			issuePipelines[issue.IssueNumber] = pipeline.Name
+		}
+	}
+	return groupStandup(report, issuePipelines), nil
+}
+
+// groupStandup sorts the items into done (closed or merged), blocked (labeled as blocked, or in a blocked pipeline)
+// and in progress (everything else). An item's pipeline is taken from the board (issuePipelines, by issue number),
+// or from the user's last move of the item if it is not on the board.
+func groupStandup(report *standupReport, issuePipelines map[int]string) map[string][]*standupItem {
+	groups := map[string][]*standupItem{}
+	for _, number := range report.order {
+		item := report.items[number]
+		pipeline, ok := issuePipelines[number]
+		if !ok {
+			pipeline = item.pipeline
+		}
+		group := standupInProgress
+		switch {
+		case item.issue.State == github.StateClosed:
+			group = standupDone
+		case isBlocked(item.issue, pipeline):
+			group = standupBlocked
+		}
+		groups[group] = append(groups[group], item)
+	}
+	return groups
+}
+
+func isBlocked(issue *github.Issue, pipeline string) bool {
+	if strings.Contains(strings.ToLower(pipeline), "block") {
+		return true
+	}
+	for _, label := range issue.Labels {
+		if strings.Contains(strings.ToLower(label.Name), "block") {
+			return true
+		}
+	}
+	return false
+}
+
+func printStandup(login string, since time.Time, groups map[string][]*standupItem, markdown bool) {
+	heading, line := "%v\n", " - %v%v %v (%v)\n"
+	if markdown {
+		heading, line = "**%v**\n", "- %v%v %v — %v\n"
+	}
+	fmt.Printf(heading, fmt.Sprintf("Standup for %v since %v", login, since.Local().Format("2006-01-02 15:04")))
+	total := 0
+	for _, group := range []string{standupDone, standupInProgress, standupBlocked} {
+		items := groups[group]
+		total += len(items)
+		if len(items) == 0 {
+			continue
+		}
+		fmt.Println()
+		fmt.Printf(heading, group)
+		for _, item := range items {
+			kind := "#"
+			if item.issue.IsPullRequest() {
+				kind = "PR #"
+			}
+			fmt.Printf(line, kind, item.issue.Number, item.issue.Title, strings.Join(item.activities, ", "))
+		}
+	}
+	if total == 0 {
+		fmt.Println()
+		fmt.Println("No activity was found.")
+	}
+}
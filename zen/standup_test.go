@@ -0,0 +1,92 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/eltorocorp/zencli/zen/github"
+	"github.com/eltorocorp/zencli/zen/zenhub"
+)
+
+func TestGroupStandup(t *testing.T) {
+	since := time.Date(2018, 1, 10, 9, 0, 0, 0, time.UTC)
+	const userID, otherID = 1, 2
+	transfer := func(userID int, hours int, from, to string) *zenhub.IssueEvent {
+		event := &zenhub.IssueEvent{Type: "transferIssue", UserID: userID, CreatedAt: since.Add(time.Duration(hours) * time.Hour)}
+		if from != "" {
+			event.FromPipeline = &zenhub.EventPipeline{Name: from}
+		}
+		if to != "" {
+			event.ToPipeline = &zenhub.EventPipeline{Name: to}
+		}
+		return event
+	}
+	open := func(number int, labels ...string) *github.Issue {
+		issue := &github.Issue{Number: number, State: github.StateOpen}
+		for _, label := range labels {
+			issue.Labels = append(issue.Labels, github.Label{Name: label})
+		}
+		return issue
+	}
+	closed := &github.Issue{Number: 1, State: github.StateClosed}
+	commented := open(2)
+	labeled := open(3, "Status: Blocked")
+	moved := open(4)
+	movedBack := open(5)
+	onBoard := open(6)
+
+	report := &standupReport{items: map[int]*standupItem{}}
+	report.add(closed, "closed")
+	report.add(closed, "commented")
+	report.add(closed, "closed")
+	report.add(commented, "commented")
+	report.add(labeled, "assigned")
+	// ZenHub returns the newest events first.
+	report.addTransfers(moved, []*zenhub.IssueEvent{
+		transfer(userID, 3, "In Progress", "Blocked"),
+		transfer(otherID, 2, "Backlog", "In Progress"),
+		transfer(userID, 1, "", "Backlog"),
+		transfer(userID, -1, "New Issues", "Backlog"),
+	}, userID, since)
+	report.addTransfers(movedBack, []*zenhub.IssueEvent{
+		transfer(userID, 2, "Blocked", "In Progress"),
+		transfer(userID, 1, "In Progress", "Blocked"),
+	}, userID, since)
+	report.addTransfers(onBoard, []*zenhub.IssueEvent{transfer(userID, 1, "In Progress", "Blocked")}, userID, since)
+
+	groups := groupStandup(report, map[int]string{2: "In Progress", 6: "In Progress"})
+
+	activities := map[int][]string{}
+	for _, items := range groups {
+		for _, item := range items {
+			activities[item.issue.Number] = item.activities
+		}
+	}
+	wantActivities := map[int][]string{
+		1: {"closed", "commented"},
+		2: {"commented"},
+		3: {"assigned"},
+		4: {"moved to Backlog", "moved from In Progress to Blocked"},
+		5: {"moved from In Progress to Blocked", "moved from Blocked to In Progress"},
+		6: {"moved from In Progress to Blocked"},
+	}
+	if !reflect.DeepEqual(activities, wantActivities) {
+		t.Errorf("expected activities %v, got %v", wantActivities, activities)
+	}
+
+	numbers := map[string][]int{}
+	for group, items := range groups {
+		for _, item := range items {
+			numbers[group] = append(numbers[group], item.issue.Number)
+		}
+	}
+	wantNumbers := map[string][]int{
+		standupDone:       {1},
+		standupInProgress: {2, 5, 6},
+		standupBlocked:    {3, 4},
+	}
+	if !reflect.DeepEqual(numbers, wantNumbers) {
+		t.Errorf("expected groups %v, got %v", wantNumbers, numbers)
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/eltorocorp/zencli/zen/github"
+)
+
+// velocityBarWidth is the width of the longest bar in the velocity chart.
+const velocityBarWidth = 40
+
+// velocityPeriod is the work completed in a milestone or a week.
+type velocityPeriod struct {
+	name   string
+	points int
+	issues int
+}
+
+// Velocity reports the story points and issues completed in each of the last milestones (or weeks, if by is
+// "week"), with their averages, the trend, and a bar chart of the points. Only milestones that are closed or past
+// their due date are included, and the current week is left out, since they are not finished.
+func (a *Actions) Velocity(last int, by string) error {
+	if last <= 0 {
+		last = 6
+	}
+	var periods []*velocityPeriod
+	var err error
+	if by == "week" {
+		periods, err = a.weeklyVelocity(last, time.Now())
+	} else {
+		periods, err = a.milestoneVelocity(last, time.Now())
+	}
+	if err != nil {
+		return err
+	}
+	if len(periods) == 0 {
+		fmt.Printf("\rThere are no finished milestones in %v.\n", a.githubAPI.RepoName)
+		return nil
+	}
+	printVelocity(periods, by)
+	return nil
+}
+
+// milestoneVelocity returns the completed work in the last finished milestones, oldest first.
+func (a *Actions) milestoneVelocity(last int, now time.Time) ([]*velocityPeriod, error) {
+	fmt.Printf("Fetching milestones for %v", a.githubAPI.RepoName)
+	milestones, err := a.githubAPI.GetMilestones("all")
+	if err != nil {
+		return nil, err
+	}
+	finished := []*github.Milestone{}
+	for _, milestone := range milestones {
+		if milestone.State == github.StateClosed || (milestone.DueOn != nil && milestone.DueOn.Before(now)) {
+			finished = append(finished, milestone)
+		}
+	}
+	sort.SliceStable(finished, func(i, j int) bool { return milestoneEnd(finished[i]).Before(milestoneEnd(finished[j])) })
+	if len(finished) > last {
+		finished = finished[len(finished)-last:]
+	}
+
+	estimates := map[int]int{}
+	periods := []*velocityPeriod{}
+	for _, milestone := range finished {
+		issues, err := a.githubAPI.GetIssuesForMilestone(milestone.Number)
+		if err != nil {
+			return nil, err
+		}
+		period := &velocityPeriod{name: milestone.Title}
+		for _, issue := range issues {
+			if issue.State != github.StateClosed || issue.IsPullRequest() {
+				continue
+			}
+			points, err := a.estimate(estimates, issue.Number)
+			if err != nil {
+				return nil, err
+			}
+			period.points += points
+			period.issues++
+		}
+		periods = append(periods, period)
+	}
+	return periods, nil
+}
+
+// weeklyVelocity returns the completed work in each of the last full weeks (starting on Monday), oldest first.
+func (a *Actions) weeklyVelocity(last int, now time.Time) ([]*velocityPeriod, error) {
+	end := startOfWeek(now)
+	start := end.AddDate(0, 0, -7*last)
+	fmt.Printf("Fetching issues closed since %v", start.Format(dateFormat))
+	issues, err := a.githubAPI.SearchIssues(fmt.Sprintf("is:issue closed:%v..%v", start.Format(dateFormat), end.AddDate(0, 0, -1).Format(dateFormat)))
+	if err != nil {
+		return nil, err
+	}
+
+	periods := []*velocityPeriod{}
+	for week := start; week.Before(end); week = week.AddDate(0, 0, 7) {
+		periods = append(periods, &velocityPeriod{name: "week of " + week.Format(dateFormat)})
+	}
+	estimates := map[int]int{}
+	for _, issue := range issues {
+		if issue.ClosedAt == nil {
+			continue
+		}
+		closedAt := issue.ClosedAt.In(now.Location())
+		if closedAt.Before(start) || !closedAt.Before(end) {
+			continue
+		}
+		points, err := a.estimate(estimates, issue.Number)
+		if err != nil {
+			return nil, err
+		}
+		// Round the days, since a week that changes to or from daylight saving time is an hour shorter or longer.
+		period := periods[int(math.Round(startOfWeek(closedAt).Sub(start).Hours()/24))/7]
+		period.points += points
+		period.issues++
+	}
+	return periods, nil
+}
+
+// estimate returns the ZenHub estimate of an issue, which is fetched individually since closed issues are not on
+// the board. The estimates are memoized in the supplied map.
+func (a *Actions) estimate(estimates map[int]int, issue int) (int, error) {
+	if points, ok := estimates[issue]; ok {
+		return points, nil
+	}
+	issueData, err := a.zenHubAPI.GetIssueData(issue)
+	if err != nil {
+		return 0, err
+	}
+	estimates[issue] = issueData.Estimate.Value
+	return issueData.Estimate.Value, nil
+}
+
+// milestoneEnd returns when a milestone finished: its due date, or when it was closed if it has no due date.
+func milestoneEnd(milestone *github.Milestone) time.Time {
+	switch {
+	case milestone.DueOn != nil:
+		return *milestone.DueOn
+	case milestone.ClosedAt != nil:
+		return *milestone.ClosedAt
+	}
+	return milestone.CreatedAt
+}
+
+// startOfWeek returns midnight on the Monday of the week that t is in.
+func startOfWeek(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+func printVelocity(periods []*velocityPeriod, by string) {
+	unit := "milestone"
+	if by == "week" {
+		unit = "week"
+	}
+	maxPoints, totalPoints, totalIssues, nameWidth := 0, 0, 0, 0
+	for _, period := range periods {
+		if period.points > maxPoints {
+			maxPoints = period.points
+		}
+		if len(period.name) > nameWidth {
+			nameWidth = len(period.name)
+		}
+		totalPoints += period.points
+		totalIssues += period.issues
+	}
+
+	fmt.Printf("\rVelocity for the last %v %vs\n", len(periods), unit)
+	for _, period := range periods {
+		bar := 0
+		if maxPoints > 0 {
+			bar = int(math.Round(float64(period.points) / float64(maxPoints) * velocityBarWidth))
+		}
+		fmt.Printf(" %v %v %4d points, %v issues\n", pr(period.name, nameWidth+1), pr(strings.Repeat("#", bar), velocityBarWidth), period.points, period.issues)
+	}
+
+	count := float64(len(periods))
+	fmt.Printf("\nAverage: %.1f points and %.1f issues per %v\n", float64(totalPoints)/count, float64(totalIssues)/count, unit)
+	if len(periods) > 1 {
+		points := []float64{}
+		for _, period := range periods {
+			points = append(points, float64(period.points))
+		}
+		slope := trend(points)
+		direction := "steady"
+		switch {
+		case slope >= 0.5:
+			direction = "rising"
+		case slope <= -0.5:
+			direction = "falling"
+		}
+		fmt.Printf("Trend:   %v (%+.1f points per %v)\n", direction, slope, unit)
+	}
+}
+
+// trend returns the slope of the least squares line through the values, which are evenly spaced.
+func trend(values []float64) float64 {
+	n := float64(len(values))
+	sumX, sumY, sumXY, sumXX := 0.0, 0.0, 0.0, 0.0
+	for i, y := range values {
+		x := float64(i)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0
+	}
+	return (n*sumXY - sumX*sumY) / denominator
+}
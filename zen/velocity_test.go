@@ -0,0 +1,63 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/eltorocorp/zencli/zen/github"
+)
+
+func TestTrend(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   float64
+	}{
+		{"empty", nil, 0},
+		{"single", []float64{8}, 0},
+		{"flat", []float64{5, 5, 5}, 0},
+		{"rising", []float64{1, 2, 3, 4}, 1},
+		{"falling", []float64{20, 15, 10}, -5},
+		{"noisy", []float64{10, 14, 12, 16}, 1.6},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := trend(test.values); math.Abs(got-test.want) > 1e-9 {
+				t.Errorf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestStartOfWeek(t *testing.T) {
+	monday := time.Date(2018, 1, 15, 0, 0, 0, 0, time.Local)
+	for day := 0; day < 7; day++ {
+		at := monday.AddDate(0, 0, day).Add(13 * time.Hour)
+		if got := startOfWeek(at); !got.Equal(monday) {
+			t.Errorf("expected the week of %v to start on %v, got %v", at, monday, got)
+		}
+	}
+}
+
+func TestMilestoneEnd(t *testing.T) {
+	created := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	due := time.Date(2018, 1, 15, 0, 0, 0, 0, time.UTC)
+	closed := time.Date(2018, 1, 16, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		milestone *github.Milestone
+		want      time.Time
+	}{
+		{"due and closed", &github.Milestone{CreatedAt: created, DueOn: &due, ClosedAt: &closed}, due},
+		{"closed", &github.Milestone{CreatedAt: created, ClosedAt: &closed}, closed},
+		{"neither", &github.Milestone{CreatedAt: created}, created},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := milestoneEnd(test.milestone); !got.Equal(test.want) {
+				t.Errorf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
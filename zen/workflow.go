@@ -0,0 +1,219 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/eltorocorp/zencli/zen/git"
+	"github.com/eltorocorp/zencli/zen/github"
+	"github.com/eltorocorp/zencli/zen/zenhub"
+)
+
+// workflowSection is the section of the config file that configures the branch workflow (start and finish).
+const workflowSection = "workflow"
+
+// Workflow settings, and their defaults.
+const (
+	inProgressPipelineKey     = "in_progress_pipeline"
+	defaultInProgressPipeline = "In Progress"
+	branchTemplateKey         = "branch_template"
+	branchPatternKey          = "branch_pattern"
+	reviewPipelineKey         = "review_pipeline"
+	defaultReviewPipeline     = "Review"
+	reviewersKey              = "reviewers"
+)
+
+// workflowSetting returns the value of a setting in the workflow section of the config file, or the default if it
+// is not set.
+func workflowSetting(key, defaultValue string) (string, error) {
+	file, err := loadConfig()
+	if err != nil {
+		return "", err
+	}
+	if value, ok := file.Get(workflowSection, key); ok && value != "" {
+		return value, nil
+	}
+	return defaultValue, nil
+}
+
+// openRepo opens the local repository that zen was run in.
+func (a *Actions) openRepo() (*git.Repo, error) {
+	repo, err := git.Open("")
+	if err != nil {
+		return nil, err
+	}
+	repo.SetDryRun(a.dryRun)
+	return repo, nil
+}
+
+// Start assigns the current user to the specified issue, moves it to the in progress pipeline, and checks out a
+// branch for it in the local repository. Unless force is true, the working tree must be clean.
+func (a *Actions) Start(issue int, force bool) error {
+	repo, err := a.openRepo()
+	if err != nil {
+		return err
+	}
+	if !force {
+		dirty, err := repo.IsDirty()
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return fmt.Errorf("the working tree in %v has uncommitted changes; commit or stash them, or use --force", repo.Dir)
+		}
+	}
+
+	pipelineName, err := workflowSetting(inProgressPipelineKey, defaultInProgressPipeline)
+	if err != nil {
+		return err
+	}
+	branchTemplate, err := workflowSetting(branchTemplateKey, git.DefaultBranchTemplate)
+	if err != nil {
+		return err
+	}
+	githubIssue, err := a.githubAPI.GetIssue(issue)
+	if err != nil {
+		return err
+	}
+	branch, err := git.BranchName(branchTemplate, git.BranchData{
+		Number: issue,
+		Title:  githubIssue.Title,
+		Repo:   a.githubAPI.RepoName,
+	})
+	if err != nil {
+		return err
+	}
+	// Look up the pipeline before changing anything, so a misconfigured pipeline does not leave the issue
+	// half started.
+	_, err = a.zenHubAPI.GetPipelineID(pipelineName)
+	if err != nil {
+		return err
+	}
+
+	err = a.PickUp(issue)
+	if err != nil {
+		return err
+	}
+	err = a.Move(issue, pipelineName)
+	if err != nil {
+		return err
+	}
+	created, err := repo.Checkout(branch)
+	if err != nil {
+		return err
+	}
+	if created {
+		fmt.Printf("Created and checked out branch %v.\n", branch)
+	} else {
+		fmt.Printf("Checked out the existing branch %v.\n", branch)
+	}
+	return nil
+}
+
+// CurrentIssue returns the issue that the checked out branch is for, which is found with the branch_pattern
+// setting.
+func (a *Actions) CurrentIssue() (int, error) {
+	pattern, err := workflowSetting(branchPatternKey, git.DefaultBranchPattern)
+	if err != nil {
+		return 0, err
+	}
+	branch, err := git.CurrentBranch("")
+	if err != nil {
+		return 0, err
+	}
+	return git.IssueFromBranch(branch, pattern)
+}
+
+// Finish opens a pull request for the checked out branch against the repository's default branch, requests reviews
+// from the configured reviewers, moves the branch's issue to the review pipeline, and connects the pull request to
+// the issue in ZenHub. The branch must already have been pushed.
+func (a *Actions) Finish(draft bool) error {
+	branch, err := git.CurrentBranch("")
+	if err != nil {
+		return err
+	}
+	issue, err := a.CurrentIssue()
+	if err != nil {
+		return err
+	}
+	repository, err := a.githubAPI.GetRepo()
+	if err != nil {
+		return err
+	}
+	if branch == repository.DefaultBranch {
+		return fmt.Errorf("%v is the default branch; check out the branch for an issue first", branch)
+	}
+
+	pipelineName, err := workflowSetting(reviewPipelineKey, defaultReviewPipeline)
+	if err != nil {
+		return err
+	}
+	reviewers, err := workflowSetting(reviewersKey, "")
+	if err != nil {
+		return err
+	}
+	// Look up the pipeline and check that the pull request can be connected before changing anything, so a
+	// misconfigured pipeline or a missing key does not leave the issue half finished.
+	_, err = a.zenHubAPI.GetPipelineID(pipelineName)
+	if err != nil {
+		return err
+	}
+	if !a.zenHubAPI.HasGraphQLKey() && a.dryRun == nil {
+		return zenhub.ErrNoGraphQLKey
+	}
+	githubIssue, err := a.githubAPI.GetIssue(issue)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Opening a pull request for %v into %v...\n", branch, repository.DefaultBranch)
+	pullRequest, err := a.githubAPI.CreatePullRequest(&github.NewPullRequest{
+		Title: githubIssue.Title,
+		Head:  branch,
+		Base:  repository.DefaultBranch,
+		Body:  fmt.Sprintf("Closes #%v", issue),
+		Draft: draft,
+	})
+	if statusError, ok := err.(*github.StatusError); ok && statusError.StatusCode == http.StatusUnprocessableEntity {
+		return fmt.Errorf("unable to open a pull request for %v; has it been pushed, and is there already a pull request for it? (%v)", branch, err)
+	}
+	if err != nil {
+		return err
+	}
+	a.reportf("Opened pull request %v: %v\n", pullRequest.Number, pullRequest.HTMLURL)
+
+	if request := reviewRequest(splitList(reviewers)); request != nil {
+		err = a.githubAPI.RequestReviewers(pullRequest.Number, request)
+		if err != nil {
+			return err
+		}
+		a.reportf("Requested reviews from %v.\n", reviewers)
+	}
+	err = a.Move(issue, pipelineName)
+	if err != nil {
+		return err
+	}
+	err = a.zenHubAPI.ConnectPullRequest(issue, pullRequest.Number)
+	if err == nil {
+		a.reportf("Pull request %v has been connected to issue %v.\n", pullRequest.Number, issue)
+	}
+	return err
+}
+
+// reviewRequest returns the request for the reviewers, which are logins or teams (i.e. eltorocorp/backend). It
+// returns nil if there are no reviewers.
+func reviewRequest(reviewers []string) *github.ReviewRequest {
+	if len(reviewers) == 0 {
+		return nil
+	}
+	request := &github.ReviewRequest{}
+	for _, reviewer := range reviewers {
+		if i := strings.Index(reviewer, "/"); i >= 0 {
+			request.TeamReviewers = append(request.TeamReviewers, reviewer[i+1:])
+			continue
+		}
+		request.Reviewers = append(request.Reviewers, reviewer)
+	}
+	return request
+}
@@ -0,0 +1,297 @@
+package yaml
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type line struct {
+	number int
+	indent int
+	text   string
+}
+
+type parser struct {
+	lines []line
+	pos   int
+}
+
+// Unmarshal decodes the supplied document. Mappings are returned as map[string]interface{}, sequences are
+// returned as []interface{}, and scalars are returned as strings. An empty document decodes to nil.
+func Unmarshal(data []byte) (interface{}, error) {
+	p := &parser{}
+	for i, text := range strings.Split(string(data), "\n") {
+		text = stripComment(strings.TrimRight(text, " \t\r"))
+		trimmed := strings.TrimLeft(text, " ")
+		if strings.TrimSpace(trimmed) == "" || trimmed == "---" || trimmed == "..." {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "\t") {
+			return nil, fmt.Errorf("line %v: tabs cannot be used for indentation", i+1)
+		}
+		p.lines = append(p.lines, line{number: i + 1, indent: len(text) - len(trimmed), text: strings.TrimSpace(trimmed)})
+	}
+	if len(p.lines) == 0 {
+		return nil, nil
+	}
+
+	value, err := p.parseNode(p.lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.lines) {
+		return nil, fmt.Errorf("line %v: unexpected indentation", p.lines[p.pos].number)
+	}
+	return value, nil
+}
+
+// String returns the value as a string if it is a scalar, otherwise it returns an empty string.
+func String(value interface{}) string {
+	if str, ok := value.(string); ok {
+		return str
+	}
+	return ""
+}
+
+// Strings returns the value as a list of strings. Sequences are returned item by item (ignoring anything that is not
+// a scalar), and scalars are treated as comma separated lists.
+func Strings(value interface{}) []string {
+	list := []string{}
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			if str, ok := item.(string); ok && str != "" {
+				list = append(list, str)
+			}
+		}
+	case string:
+		for _, item := range strings.Split(v, ",") {
+			item = strings.TrimSpace(item)
+			if item != "" {
+				list = append(list, item)
+			}
+		}
+	}
+	return list
+}
+
+func (p *parser) parseNode(indent int) (interface{}, error) {
+	if isSequenceItem(p.lines[p.pos].text) {
+		return p.parseSequence(indent)
+	}
+	return p.parseMapping(indent)
+}
+
+func (p *parser) parseSequence(indent int) (interface{}, error) {
+	items := []interface{}{}
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isSequenceItem(p.lines[p.pos].text) {
+		current := p.lines[p.pos]
+		rest := strings.TrimLeft(current.text[1:], " ")
+		switch {
+		case rest == "":
+			p.pos++
+			if p.pos < len(p.lines) && p.lines[p.pos].indent > indent {
+				item, err := p.parseNode(p.lines[p.pos].indent)
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, item)
+			} else {
+				items = append(items, "")
+			}
+		case isMappingEntry(rest):
+			// The item is a mapping whose first entry shares the line with the dash, so the line is rewritten
+			// as though the entry started on its own line at the same column.
+			p.lines[p.pos] = line{number: current.number, indent: indent + len(current.text) - len(rest), text: rest}
+			item, err := p.parseMapping(p.lines[p.pos].indent)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		default:
+			item, err := parseValue(rest, current.number)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+			p.pos++
+		}
+	}
+	return items, nil
+}
+
+func (p *parser) parseMapping(indent int) (interface{}, error) {
+	mapping := map[string]interface{}{}
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent {
+		current := p.lines[p.pos]
+		key, rest, ok := splitMappingEntry(current.text)
+		if !ok {
+			return nil, fmt.Errorf("line %v: expected a 'key: value' pair", current.number)
+		}
+		p.pos++
+
+		if rest != "" {
+			value, err := parseValue(rest, current.number)
+			if err != nil {
+				return nil, err
+			}
+			mapping[key] = value
+			continue
+		}
+
+		// A sequence is allowed to start at the same indentation as its key.
+		if p.pos < len(p.lines) &&
+			(p.lines[p.pos].indent > indent || (p.lines[p.pos].indent == indent && isSequenceItem(p.lines[p.pos].text))) {
+			value, err := p.parseNode(p.lines[p.pos].indent)
+			if err != nil {
+				return nil, err
+			}
+			mapping[key] = value
+			continue
+		}
+		mapping[key] = ""
+	}
+	if p.pos < len(p.lines) && p.lines[p.pos].indent > indent {
+		return nil, fmt.Errorf("line %v: unexpected indentation", p.lines[p.pos].number)
+	}
+	return mapping, nil
+}
+
+func parseValue(text string, lineNumber int) (interface{}, error) {
+	if text == "|" || text == ">" || strings.HasPrefix(text, "|") || strings.HasPrefix(text, ">") {
+		return nil, fmt.Errorf("line %v: multi-line scalars are not supported", lineNumber)
+	}
+	if strings.HasPrefix(text, "{") {
+		return nil, fmt.Errorf("line %v: flow mappings are not supported", lineNumber)
+	}
+	if !strings.HasPrefix(text, "[") {
+		return parseScalar(text, lineNumber)
+	}
+	if !strings.HasSuffix(text, "]") {
+		return nil, fmt.Errorf("line %v: unterminated sequence", lineNumber)
+	}
+
+	items := []interface{}{}
+	inner := strings.TrimSpace(text[1 : len(text)-1])
+	if inner == "" {
+		return items, nil
+	}
+	for _, item := range splitOutsideQuotes(inner, ',') {
+		value, err := parseScalar(strings.TrimSpace(item), lineNumber)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, value)
+	}
+	return items, nil
+}
+
+func parseScalar(text string, lineNumber int) (string, error) {
+	if len(text) > 0 && (text[0] == '"' || text[0] == '\'') {
+		if len(text) < 2 || text[len(text)-1] != text[0] {
+			return "", fmt.Errorf("line %v: unterminated string", lineNumber)
+		}
+		if text[0] == '\'' {
+			return strings.Replace(text[1:len(text)-1], "''", "'", -1), nil
+		}
+		value, err := strconv.Unquote(text)
+		if err != nil {
+			return "", fmt.Errorf("line %v: invalid string %v", lineNumber, text)
+		}
+		return value, nil
+	}
+	if text == "~" || text == "null" {
+		return "", nil
+	}
+	return text, nil
+}
+
+func isSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+func isMappingEntry(text string) bool {
+	_, _, ok := splitMappingEntry(text)
+	return ok
+}
+
+// splitMappingEntry splits a "key: value" line into its key and (unparsed) value.
+func splitMappingEntry(text string) (string, string, bool) {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		switch {
+		case quote != 0:
+			i, quote = scanQuoted(text, i, quote)
+		case opensQuote(text, i):
+			quote = text[i]
+		case text[i] == ':' && (i == len(text)-1 || text[i+1] == ' '):
+			key, err := parseScalar(strings.TrimSpace(text[:i]), 0)
+			if err != nil || key == "" {
+				return "", "", false
+			}
+			return key, strings.TrimSpace(text[i+1:]), true
+		case text[i] == '[' && i == 0:
+			return "", "", false
+		}
+	}
+	return "", "", false
+}
+
+// stripComment removes any trailing comment from the line, ignoring '#' characters inside quotes or words.
+func stripComment(text string) string {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		switch {
+		case quote != 0:
+			i, quote = scanQuoted(text, i, quote)
+		case opensQuote(text, i):
+			quote = text[i]
+		case text[i] == '#' && (i == 0 || text[i-1] == ' ' || text[i-1] == '\t'):
+			return strings.TrimRight(text[:i], " \t")
+		}
+	}
+	return text
+}
+
+func splitOutsideQuotes(text string, separator byte) []string {
+	parts := []string{}
+	var quote byte
+	start := 0
+	for i := 0; i < len(text); i++ {
+		switch {
+		case quote != 0:
+			i, quote = scanQuoted(text, i, quote)
+		case opensQuote(text, i):
+			quote = text[i]
+		case text[i] == separator:
+			parts = append(parts, text[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, text[start:])
+}
+
+// scanQuoted handles the character at the specified index within a quoted scalar. It returns the index of the last
+// character handled, which skips over escaped quotes (a doubled quote in single quotes, or a backslash escape in double
+// quotes), and the quote that is still open (or 0 if the character closed it).
+func scanQuoted(text string, i int, quote byte) (int, byte) {
+	switch {
+	case quote == '"' && text[i] == '\\' && i+1 < len(text):
+		return i + 1, quote
+	case quote == '\'' && text[i] == '\'' && i+1 < len(text) && text[i+1] == '\'':
+		return i + 1, quote
+	case text[i] == quote:
+		return i, 0
+	}
+	return i, quote
+}
+
+// opensQuote reports whether the character at the specified index starts a quoted scalar. Quote characters within
+// plain scalars (such as the apostrophe in "isn't") do not.
+func opensQuote(text string, i int) bool {
+	if text[i] != '"' && text[i] != '\'' {
+		return false
+	}
+	return i == 0 || strings.IndexByte(" \t:[,-", text[i-1]) >= 0
+}
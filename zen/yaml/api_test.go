@@ -0,0 +1,141 @@
+package yaml
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUnmarshal(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want interface{}
+	}{
+		{"empty", "", nil},
+		{"only comments and markers", "# comment\n---\n...\n", nil},
+		{"plain scalars", "name: Bug report\nabout: Report a bug\n", map[string]interface{}{"name": "Bug report", "about": "Report a bug"}},
+		{"empty and null values", "a:\nb: ~\nc: null\n", map[string]interface{}{"a": "", "b": "", "c": ""}},
+		{"colons in values", "url: http://example.com\ntime: 12:30\n", map[string]interface{}{"url": "http://example.com", "time": "12:30"}},
+		{"double quoted", `title: "[BUG] \"quoted\" \u00e9"`, map[string]interface{}{"title": `[BUG] "quoted" é`}},
+		{"single quoted", `title: 'it''s # not a comment'`, map[string]interface{}{"title": "it's # not a comment"}},
+		{"escaped double quote", `title: "a \" # b" # c`, map[string]interface{}{"title": `a " # b`}},
+		{"quoted key", `"a: b": c`, map[string]interface{}{"a: b": "c"}},
+		{"apostrophe in plain scalar", "about: isn't quoted\n", map[string]interface{}{"about": "isn't quoted"}},
+		{"comments", "# heading\nname: x # trailing\nissue: '#1'\nlabel: a#b\n", map[string]interface{}{"name": "x", "issue": "#1", "label": "a#b"}},
+		{"windows line endings", "a: 1\r\nb: 2\r\n", map[string]interface{}{"a": "1", "b": "2"}},
+		{"flow sequence", "labels: [bug, 'needs triage', \"a,b\"]\n", map[string]interface{}{"labels": []interface{}{"bug", "needs triage", "a,b"}}},
+		{"empty flow sequence", "labels: []\n", map[string]interface{}{"labels": []interface{}{}}},
+		{"block sequence", "labels:\n  - bug\n  - 'help wanted'\n", map[string]interface{}{"labels": []interface{}{"bug", "help wanted"}}},
+		{"sequence at the key's indentation", "labels:\n- bug\n- docs\nname: x\n", map[string]interface{}{"labels": []interface{}{"bug", "docs"}, "name": "x"}},
+		{"top level sequence", "- a\n- b\n", []interface{}{"a", "b"}},
+		{"empty sequence item", "-\n- b\n", []interface{}{"", "b"}},
+		{
+			"nested mappings",
+			"a:\n  b:\n    c: 1\n  d: 2\ne: 3\n",
+			map[string]interface{}{"a": map[string]interface{}{"b": map[string]interface{}{"c": "1"}, "d": "2"}, "e": "3"},
+		},
+		{
+			"sequence of mappings",
+			"steps:\n  - name: one\n    run: a\n  - name: two\n",
+			map[string]interface{}{"steps": []interface{}{
+				map[string]interface{}{"name": "one", "run": "a"},
+				map[string]interface{}{"name": "two"},
+			}},
+		},
+		{
+			"nested sequence",
+			"- \n  - a\n  - b\n- c\n",
+			[]interface{}{[]interface{}{"a", "b"}, "c"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Unmarshal([]byte(test.doc))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("expected %#v, got %#v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want string
+	}{
+		{"tab indentation", "a:\n\tb: 1\n", "line 2: tabs cannot be used for indentation"},
+		{"not a mapping entry", "a: 1\njust text\n", "line 2: expected a 'key: value' pair"},
+		{"unexpected indentation", "a: 1\n    b: 2\n", "line 2: unexpected indentation"},
+		{"dedent below the document", "  a: 1\nb: 2\n", "line 2: unexpected indentation"},
+		{"unterminated double quote", "a: \"abc\n", "line 1: unterminated string"},
+		{"unterminated single quote", "a: 'abc\n", "line 1: unterminated string"},
+		{"invalid escape", `a: "\q"`, `line 1: invalid string "\q"`},
+		{"unterminated flow sequence", "a: [b, c\n", "line 1: unterminated sequence"},
+		{"flow mapping", "a: {b: c}\n", "line 1: flow mappings are not supported"},
+		{"literal block", "a: |\n  text\n", "line 1: multi-line scalars are not supported"},
+		{"folded block", "a: >\n  text\n", "line 1: multi-line scalars are not supported"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Unmarshal([]byte(test.doc))
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			if err.Error() != test.want {
+				t.Errorf("expected %q, got %q", test.want, err.Error())
+			}
+		})
+	}
+}
+
+func TestStrings(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  []string
+	}{
+		{"nil", nil, []string{}},
+		{"comma separated", "bug, help wanted,,", []string{"bug", "help wanted"}},
+		{"sequence", []interface{}{"a", "", map[string]interface{}{}, "b"}, []string{"a", "b"}},
+		{"mapping", map[string]interface{}{"a": "b"}, []string{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Strings(test.value); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+
+	if got := String([]interface{}{"a"}); got != "" {
+		t.Errorf("expected a sequence to have no string value, got %q", got)
+	}
+	if got := String("a"); got != "a" {
+		t.Errorf("expected %q, got %q", "a", got)
+	}
+}
+
+func TestStripComment(t *testing.T) {
+	tests := map[string]string{
+		"a: b # c":        "a: b",
+		"# c":             "",
+		"a: 'b # c'":      "a: 'b # c'",
+		`a: "b # c" # d`:  `a: "b # c"`,
+		"a: b#c":          "a: b#c",
+		"a: isn't # c":    "a: isn't",
+		"a: \"it's\" # c": "a: \"it's\"",
+	}
+	for text, want := range tests {
+		if got := stripComment(text); got != want {
+			t.Errorf("stripComment(%q): expected %q, got %q", text, want, got)
+		}
+	}
+	if strings.Contains(stripComment("a: b\t# c"), "#") {
+		t.Errorf("expected a comment after a tab to be removed")
+	}
+}
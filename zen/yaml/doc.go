@@ -0,0 +1,5 @@
+// Package yaml decodes the small subset of YAML used by github issue templates and zen's own definition files.
+//
+// Block mappings, block sequences, flow sequences ([a, b]), quoted and plain scalars, and comments are supported.
+// Anchors, tags, flow mappings and multi-line scalars are not.
+package yaml
@@ -3,22 +3,46 @@ package zenhub
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/eltorocorp/zencli/zen/github"
 )
 
 const (
 	zenhubRoot = "https://api.zenhub.io"
+	// graphQLURI is the endpoint of ZenHub's GraphQL API, which is needed for features that the REST API lacks.
+	graphQLURI = "https://api.zenhub.com/public/graphql"
 )
 
+// ErrNoGraphQLKey is returned by the methods that use ZenHub's GraphQL API when no key has been set for it (see
+// SetGraphQLKey).
+var ErrNoGraphQLKey = errors.New("ZenHub's GraphQL API needs a personal API key; create one at https://app.zenhub.com/settings/tokens and set ZENCLI_ZENHUBGRAPHQLTOKEN to it")
+
 // API provides methods for interacting with ZenHub.
 type API struct {
 	githubAPI       *github.API
 	zenHubAuthToken string
+	// graphQLKey is the personal API key for ZenHub's GraphQL API, which does not accept the REST API's token.
+	graphQLKey string
+
+	// mutex guards pipelines, which holds the pipelines from the last time the board was fetched, and the
+	// selected workspace.
+	mutex             sync.Mutex
+	pipelines         []Pipeline
+	workspaceName     string
+	workspace         *Workspace
+	workspaceResolved bool
+
+	// dryRun receives a description of each request that would change data, instead of it being sent, if it is
+	// not nil.
+	dryRun io.Writer
 }
 
 // New returns a reference to a ZenHub API
@@ -29,86 +53,262 @@ func New(zenHubAuthToken string, githubAPI *github.API) *API {
 	}
 }
 
-// GetPipelines returns a list of pipelines.
+// WithGitHubAPI returns a reference to a ZenHub API for the repository of the supplied github API, using the same
+// credentials.
+func (a *API) WithGitHubAPI(githubAPI *github.API) *API {
+	api := New(a.zenHubAuthToken, githubAPI)
+	api.graphQLKey = a.graphQLKey
+	api.dryRun = a.dryRun
+	api.workspaceName = a.workspaceName
+	return api
+}
+
+// SetDryRun makes the API write a description of each request that would change data to w, instead of sending
+// it. Requests that only read data are still sent. Passing nil turns the dry run off.
+func (a *API) SetDryRun(w io.Writer) {
+	a.dryRun = w
+}
+
+// GetPipelines returns a list of pipelines, with the issues from the target repository. If the repository is in a
+// workspace, the workspace's board is used.
 func (a *API) GetPipelines() (*Pipelines, error) {
 	repoID, err := a.githubAPI.GetRepoID()
 	if err != nil {
 		return nil, err
 	}
-
-	client := http.DefaultClient
-	getPipelinesURI := fmt.Sprintf("%v/p1/repositories/%v/board", zenhubRoot, *repoID)
-	request, err := a.createDefaultRequest(http.MethodGet, getPipelinesURI)
+	workspace, err := a.GetWorkspace()
 	if err != nil {
 		return nil, err
 	}
 
-	response, err := client.Do(request)
+	pipelines, err := a.getBoard(workspace, *repoID)
 	if err != nil {
 		return nil, err
 	}
 
-	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("the get pipelines endpoint returned %v", response.StatusCode)
-	}
+	a.mutex.Lock()
+	a.pipelines = pipelines.List
+	a.mutex.Unlock()
+	return pipelines, nil
+}
 
-	body, err := ioutil.ReadAll(response.Body)
+// boardWorkers is the most repository boards that are fetched at once.
+const boardWorkers = 8
+
+// GetWorkspaceBoard returns the pipelines of the workspace's board, with the issues from every repository in the
+// workspace. The RepoID of each issue identifies its repository. If the target repository is not in a workspace,
+// only its own issues are returned.
+//
+// The repositories' boards are fetched concurrently. A board that cannot be fetched for any repository other than
+// the target repository is left out and its error is recorded in Unreadable, so that one inaccessible repository
+// does not hide the rest of the workspace.
+func (a *API) GetWorkspaceBoard() (*Pipelines, error) {
+	repoID, err := a.githubAPI.GetRepoID()
 	if err != nil {
 		return nil, err
 	}
+	workspace, err := a.GetWorkspace()
+	if err != nil {
+		return nil, err
+	}
+	repoIDs := []int{*repoID}
+	if workspace != nil && len(workspace.Repositories) > 0 {
+		repoIDs = workspace.Repositories
+	}
 
-	pipelines := new(Pipelines)
-	err = json.Unmarshal(body, pipelines)
+	boards := make([]*Pipelines, len(repoIDs))
+	errs := make([]error, len(repoIDs))
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < boardWorkers && i < len(repoIDs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range work {
+				boards[index], errs[index] = a.getBoard(workspace, repoIDs[index])
+			}
+		}()
+	}
+	for index := range repoIDs {
+		work <- index
+	}
+	close(work)
+	wg.Wait()
+
+	board := &Pipelines{Unreadable: map[int]error{}}
+	indexes := map[string]int{}
+	for index, id := range repoIDs {
+		if errs[index] != nil {
+			if id == *repoID {
+				return nil, errs[index]
+			}
+			board.Unreadable[id] = errs[index]
+			continue
+		}
+		for _, pipeline := range boards[index].List {
+			for i := range pipeline.Issues {
+				pipeline.Issues[i].RepoID = id
+			}
+			if existing, ok := indexes[pipeline.ID]; ok {
+				board.List[existing].Issues = append(board.List[existing].Issues, pipeline.Issues...)
+				continue
+			}
+			indexes[pipeline.ID] = len(board.List)
+			board.List = append(board.List, pipeline)
+		}
+	}
+	return board, nil
+}
 
+// getBoard returns the board for the specified repository, from the workspace if there is one.
+func (a *API) getBoard(workspace *Workspace, repoID int) (*Pipelines, error) {
+	getBoardURI := fmt.Sprintf("%v/p1/repositories/%v/board", zenhubRoot, repoID)
+	if workspace != nil {
+		getBoardURI = fmt.Sprintf("%v/p2/workspaces/%v/repositories/%v/board", zenhubRoot, workspace.ID, repoID)
+	}
+	pipelines := new(Pipelines)
+	err := a.doRequest(http.MethodGet, getBoardURI, nil, pipelines, http.StatusOK, "get pipelines")
 	return pipelines, err
 }
 
-// MovePipeline moves the specified issue to the specified pipeline.
-func (a *API) MovePipeline(issue int, pipelineID string) error {
+// GetWorkspaces returns the workspaces that the target repository belongs to.
+func (a *API) GetWorkspaces() ([]*Workspace, error) {
 	repoID, err := a.githubAPI.GetRepoID()
 	if err != nil {
-		return err
+		return nil, err
 	}
+	getWorkspacesURI := fmt.Sprintf("%v/p2/repositories/%v/workspaces", zenhubRoot, *repoID)
+	workspaces := []*Workspace{}
+	err = a.doRequest(http.MethodGet, getWorkspacesURI, nil, &workspaces, http.StatusOK, "get workspaces")
+	return workspaces, err
+}
 
-	pipelineMove := &PipelineMove{
-		PipelineID: pipelineID,
-		Position:   "top",
+// SetGraphQLKey sets the personal API key that is used for ZenHub's GraphQL API.
+func (a *API) SetGraphQLKey(key string) {
+	a.graphQLKey = key
+}
+
+// HasGraphQLKey returns true if a key has been set for ZenHub's GraphQL API.
+func (a *API) HasGraphQLKey() bool {
+	return a.graphQLKey != ""
+}
+
+// SetWorkspace selects the workspace (by name or ID) to use for the board. If no workspace is selected, the
+// first workspace that the repository belongs to is used.
+func (a *API) SetWorkspace(workspace string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.workspaceName = workspace
+	a.workspace = nil
+	a.workspaceResolved = false
+}
+
+// GetWorkspace returns the selected workspace (see SetWorkspace), or nil if the repository does not belong to a
+// workspace. The workspace is only looked up once.
+func (a *API) GetWorkspace() (*Workspace, error) {
+	a.mutex.Lock()
+	resolved, workspace, name := a.workspaceResolved, a.workspace, a.workspaceName
+	a.mutex.Unlock()
+	if resolved {
+		return workspace, nil
 	}
-	pipelineMoveJSON, err := json.Marshal(pipelineMove)
+
+	workspaces, err := a.GetWorkspaces()
 	if err != nil {
-		return err
+		return nil, err
+	}
+	workspace, err = selectWorkspace(workspaces, name)
+	if err != nil {
+		return nil, err
 	}
 
-	client := http.DefaultClient
-	getPipelinesURI := fmt.Sprintf("%v/p1/repositories/%v/issues/%v/moves", zenhubRoot, *repoID, issue)
-	request, err := a.createDefaultRequest(http.MethodPost, getPipelinesURI)
+	a.mutex.Lock()
+	a.workspace, a.workspaceResolved = workspace, true
+	a.mutex.Unlock()
+	return workspace, nil
+}
+
+func selectWorkspace(workspaces []*Workspace, name string) (*Workspace, error) {
+	if name == "" {
+		if len(workspaces) == 0 {
+			return nil, nil
+		}
+		return workspaces[0], nil
+	}
+	names := []string{}
+	for _, workspace := range workspaces {
+		if strings.EqualFold(workspace.Name, name) || workspace.ID == name {
+			return workspace, nil
+		}
+		names = append(names, workspace.Name)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("workspace '%v' was not found; the repository does not belong to any workspaces", name)
+	}
+	return nil, fmt.Errorf("workspace '%v' was not found; the workspaces for the repository are: %v", name, strings.Join(names, ", "))
+}
+
+// GetPipelineNames returns the names of the pipelines on the board. The board is only fetched if it has not
+// already been fetched, since pipelines rarely change.
+func (a *API) GetPipelineNames() ([]string, error) {
+	pipelines, err := a.boardPipelines()
 	if err != nil {
-		return err
+		return nil, err
 	}
+	names := []string{}
+	for _, pipeline := range pipelines {
+		names = append(names, pipeline.Name)
+	}
+	return names, nil
+}
 
-	request.Header.Add("Content-Type", "application/json")
-	request.Body = ioutil.NopCloser(bytes.NewReader(pipelineMoveJSON))
-	response, err := client.Do(request)
+func (a *API) boardPipelines() ([]Pipeline, error) {
+	a.mutex.Lock()
+	pipelines := a.pipelines
+	a.mutex.Unlock()
+	if pipelines != nil {
+		return pipelines, nil
+	}
 
+	board, err := a.GetPipelines()
+	if err != nil {
+		return nil, err
+	}
+	return board.List, nil
+}
+
+// MovePipeline moves the specified issue to the specified pipeline, on the workspace's board if the repository
+// belongs to a workspace.
+func (a *API) MovePipeline(issue int, pipelineID string) error {
+	repoID, err := a.githubAPI.GetRepoID()
+	if err != nil {
+		return err
+	}
+	workspace, err := a.GetWorkspace()
 	if err != nil {
 		return err
 	}
 
-	if response.StatusCode != http.StatusOK {
-		return fmt.Errorf("the move issue endpoint returned %v", response.StatusCode)
+	pipelineMove := &PipelineMove{
+		PipelineID: pipelineID,
+		Position:   "top",
+	}
+	movePipelineURI := fmt.Sprintf("%v/p1/repositories/%v/issues/%v/moves", zenhubRoot, *repoID, issue)
+	if workspace != nil {
+		movePipelineURI = fmt.Sprintf("%v/p2/workspaces/%v/repositories/%v/issues/%v/moves", zenhubRoot, workspace.ID, *repoID, issue)
 	}
-	return nil
+	return a.doRequest(http.MethodPost, movePipelineURI, pipelineMove, nil, http.StatusOK, "move issue")
 }
 
 // GetPipelineID returns the ZenHub ID for the specified pipeline name. If the specified pipeline
 // does not exist for the current board, this method will return an empty string and an error.
 func (a *API) GetPipelineID(pipelineName string) (string, error) {
 	pipelineID := ""
-	pipelines, err := a.GetPipelines()
+	pipelines, err := a.boardPipelines()
 	if err != nil {
 		return "", err
 	}
-	for _, pipeline := range pipelines.List {
+	for _, pipeline := range pipelines {
 		if strings.ToLower(pipeline.Name) == strings.ToLower(pipelineName) {
 			pipelineID = pipeline.ID
 			break
@@ -122,6 +322,291 @@ func (a *API) GetPipelineID(pipelineName string) (string, error) {
 	return pipelineID, nil
 }
 
+// GetIssueData returns the ZenHub data (estimate, pipeline and epic status) for the specified issue.
+func (a *API) GetIssueData(issue int) (*IssueData, error) {
+	repoID, err := a.githubAPI.GetRepoID()
+	if err != nil {
+		return nil, err
+	}
+
+	getIssueURI := fmt.Sprintf("%v/p1/repositories/%v/issues/%v", zenhubRoot, *repoID, issue)
+	issueData := new(IssueData)
+	err = a.doRequest(http.MethodGet, getIssueURI, nil, issueData, http.StatusOK, "get issue data")
+	return issueData, err
+}
+
+// GetIssueEvents returns the ZenHub events for the specified issue, newest first.
+func (a *API) GetIssueEvents(issue int) ([]*IssueEvent, error) {
+	repoID, err := a.githubAPI.GetRepoID()
+	if err != nil {
+		return nil, err
+	}
+	getEventsURI := fmt.Sprintf("%v/p1/repositories/%v/issues/%v/events", zenhubRoot, *repoID, issue)
+	events := []*IssueEvent{}
+	err = a.doRequest(http.MethodGet, getEventsURI, nil, &events, http.StatusOK, "issue events")
+	return events, err
+}
+
+// GetReleases returns the release reports that include the repository.
+func (a *API) GetReleases() ([]*Release, error) {
+	repoID, err := a.githubAPI.GetRepoID()
+	if err != nil {
+		return nil, err
+	}
+	getReleasesURI := fmt.Sprintf("%v/p1/repositories/%v/reports/releases", zenhubRoot, *repoID)
+	releases := []*Release{}
+	err = a.doRequest(http.MethodGet, getReleasesURI, nil, &releases, http.StatusOK, "release reports")
+	return releases, err
+}
+
+// FindRelease returns the release report with the specified title (ignoring case) or ID, or nil if there is no such
+// release.
+func (a *API) FindRelease(release string) (*Release, error) {
+	releases, err := a.GetReleases()
+	if err != nil {
+		return nil, err
+	}
+	for _, candidate := range releases {
+		if strings.EqualFold(candidate.Title, release) || candidate.ID == release {
+			return candidate, nil
+		}
+	}
+	return nil, nil
+}
+
+// GetReleaseIssues returns the issues in the specified release, which may be in any of its repositories.
+func (a *API) GetReleaseIssues(releaseID string) ([]*ReleaseIssue, error) {
+	getIssuesURI := fmt.Sprintf("%v/p1/reports/release/%v/issues", zenhubRoot, releaseID)
+	issues := []*ReleaseIssue{}
+	err := a.doRequest(http.MethodGet, getIssuesURI, nil, &issues, http.StatusOK, "release issues")
+	return issues, err
+}
+
+// GetMilestoneStartDate returns the start date that was set for the specified milestone in ZenHub, or nil if none
+// was set.
+func (a *API) GetMilestoneStartDate(milestone int) (*time.Time, error) {
+	repoID, err := a.githubAPI.GetRepoID()
+	if err != nil {
+		return nil, err
+	}
+	getStartDateURI := fmt.Sprintf("%v/p1/repositories/%v/milestones/%v/start_date", zenhubRoot, *repoID, milestone)
+	startDate := new(MilestoneStartDate)
+	err = a.doRequest(http.MethodGet, getStartDateURI, nil, startDate, http.StatusOK, "milestone start date")
+	return startDate.StartDate, err
+}
+
+// SetEstimate sets the estimate for the specified issue.
+func (a *API) SetEstimate(issue, estimate int) error {
+	repoID, err := a.githubAPI.GetRepoID()
+	if err != nil {
+		return err
+	}
+
+	setEstimateURI := fmt.Sprintf("%v/p1/repositories/%v/issues/%v/estimate", zenhubRoot, *repoID, issue)
+	issueEstimate := &IssueEstimate{
+		Estimate: estimate,
+	}
+	return a.doRequest(http.MethodPut, setEstimateURI, issueEstimate, nil, http.StatusOK, "set estimate")
+}
+
+// AddIssueToEpic adds the specified issue to the specified epic, which is in the repository with the ID epicRepoID
+// (epics can collect issues from other repositories in the workspace).
+func (a *API) AddIssueToEpic(epicRepoID, epic, issue int) error {
+	repoID, err := a.githubAPI.GetRepoID()
+	if err != nil {
+		return err
+	}
+
+	updateEpicURI := fmt.Sprintf("%v/p1/repositories/%v/epics/%v/update_issues", zenhubRoot, epicRepoID, epic)
+	epicUpdate := &EpicUpdate{
+		AddIssues: []EpicIssue{{RepoID: *repoID, IssueNumber: issue}},
+	}
+	return a.doRequest(http.MethodPost, updateEpicURI, epicUpdate, nil, http.StatusOK, "update epic")
+}
+
+// ConnectPullRequest connects a pull request to an issue in ZenHub, so the pull request is shown with the issue
+// on the board. Both must be in the target repository.
+func (a *API) ConnectPullRequest(issue, pullRequest int) error {
+	if a.dryRun != nil {
+		_, err := fmt.Fprintf(a.dryRun, "connect pull request %v to issue %v\n", pullRequest, issue)
+		return err
+	}
+	issueID, err := a.getGraphQLIssueID(issue)
+	if err != nil {
+		return err
+	}
+	pullRequestID, err := a.getGraphQLIssueID(pullRequest)
+	if err != nil {
+		return err
+	}
+
+	const mutation = `mutation($input: CreateIssuePrConnectionInput!) {
+	createIssuePrConnection(input: $input) { issue { id } }
+}`
+	variables := map[string]interface{}{
+		"input": map[string]interface{}{"issueId": issueID, "pullRequestId": pullRequestID},
+	}
+	return a.doGraphQL(mutation, variables, nil, "connect pull request")
+}
+
+// GetConnectedPullRequests returns the pull requests that are connected to the specified issue in ZenHub, whether
+// they were connected on the board, with ConnectPullRequest, or by a "connects #123" keyword.
+func (a *API) GetConnectedPullRequests(issue int) ([]*ConnectedPullRequest, error) {
+	repoID, err := a.githubAPI.GetRepoID()
+	if err != nil {
+		return nil, err
+	}
+	const query = `query($repositoryGhId: Int!, $issueNumber: Int!) {
+	issueByInfo(repositoryGhId: $repositoryGhId, issueNumber: $issueNumber) {
+		connectedPrs { nodes { number repository { name ownerName } } }
+	}
+}`
+	variables := map[string]interface{}{"repositoryGhId": *repoID, "issueNumber": issue}
+	result := &struct {
+		IssueByInfo *struct {
+			ConnectedPrs struct {
+				Nodes []struct {
+					Number     int `json:"number"`
+					Repository struct {
+						Name      string `json:"name"`
+						OwnerName string `json:"ownerName"`
+					} `json:"repository"`
+				} `json:"nodes"`
+			} `json:"connectedPrs"`
+		} `json:"issueByInfo"`
+	}{}
+	err = a.doGraphQL(query, variables, result, "connected pull requests")
+	if err != nil {
+		return nil, err
+	}
+
+	connected := []*ConnectedPullRequest{}
+	if result.IssueByInfo == nil {
+		return connected, nil
+	}
+	for _, node := range result.IssueByInfo.ConnectedPrs.Nodes {
+		connected = append(connected, &ConnectedPullRequest{
+			Number: node.Number,
+			Repo:   node.Repository.OwnerName + "/" + node.Repository.Name,
+		})
+	}
+	return connected, nil
+}
+
+// getGraphQLIssueID returns the GraphQL ID of an issue (or pull request) in the target repository.
+func (a *API) getGraphQLIssueID(issue int) (string, error) {
+	repoID, err := a.githubAPI.GetRepoID()
+	if err != nil {
+		return "", err
+	}
+	const query = `query($repositoryGhId: Int!, $issueNumber: Int!) {
+	issueByInfo(repositoryGhId: $repositoryGhId, issueNumber: $issueNumber) { id }
+}`
+	variables := map[string]interface{}{"repositoryGhId": *repoID, "issueNumber": issue}
+	result := &struct {
+		IssueByInfo *struct {
+			ID string `json:"id"`
+		} `json:"issueByInfo"`
+	}{}
+	err = a.doGraphQL(query, variables, result, "issue")
+	if err != nil {
+		return "", err
+	}
+	if result.IssueByInfo == nil {
+		return "", fmt.Errorf("issue %v was not found in ZenHub", issue)
+	}
+	return result.IssueByInfo.ID, nil
+}
+
+// doGraphQL sends a GraphQL query or mutation, and unmarshals the data in the response into out.
+func (a *API) doGraphQL(query string, variables map[string]interface{}, out interface{}, endpoint string) error {
+	if a.graphQLKey == "" {
+		return ErrNoGraphQLKey
+	}
+	request, err := http.NewRequest(http.MethodPost, graphQLURI, nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Add("Authorization", "Bearer "+a.graphQLKey)
+
+	result := &struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}{}
+	payload := map[string]interface{}{"query": query, "variables": variables}
+	err = a.send(request, payload, result, http.StatusOK, endpoint)
+	if err != nil {
+		return err
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("the %v endpoint returned an error: %v", endpoint, result.Errors[0].Message)
+	}
+	if out == nil || len(result.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(result.Data, out)
+}
+
+// doRequest sends a request to the specified uri and verifies that the response has the expected status code.
+// If payload is non-nil it is sent as the JSON body of the request, and if out is non-nil the response body is
+// decoded into it. The endpoint name is only used to describe the endpoint in any error that is returned.
+func (a *API) doRequest(method, uri string, payload, out interface{}, expectedStatus int, endpoint string) error {
+	if a.dryRun != nil && method != http.MethodGet {
+		description := fmt.Sprintf("%v %v", method, strings.TrimPrefix(uri, zenhubRoot))
+		if payload != nil {
+			payloadJSON, err := json.Marshal(payload)
+			if err != nil {
+				return err
+			}
+			description += " " + string(payloadJSON)
+		}
+		_, err := fmt.Fprintln(a.dryRun, description)
+		return err
+	}
+
+	request, err := a.createDefaultRequest(method, uri)
+	if err != nil {
+		return err
+	}
+	return a.send(request, payload, out, expectedStatus, endpoint)
+}
+
+// send sends a request, with the payload (if it is non-nil) as its JSON body, and verifies that the response has
+// the expected status code. If out is non-nil the response body is decoded into it.
+func (a *API) send(request *http.Request, payload, out interface{}, expectedStatus int, endpoint string) error {
+	if payload != nil {
+		payloadJSON, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		request.Header.Add("Content-Type", "application/json")
+		request.Body = ioutil.NopCloser(bytes.NewReader(payloadJSON))
+		request.ContentLength = int64(len(payloadJSON))
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != expectedStatus {
+		return fmt.Errorf("the %v endpoint returned %v", endpoint, response.StatusCode)
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, out)
+}
+
 func (a *API) createDefaultRequest(method, uri string) (*http.Request, error) {
 	request, err := http.NewRequest(method, uri, nil)
 	if err != nil {
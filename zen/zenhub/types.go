@@ -1,8 +1,13 @@
 package zenhub
 
+import "time"
+
 // Pipelines represents a slice of zenhub pipelines.
 type Pipelines struct {
 	List []Pipeline `json:"pipelines"`
+	// Unreadable holds the error for each repository (by ID) whose board could not be fetched. It is only set by
+	// GetWorkspaceBoard.
+	Unreadable map[int]error `json:"-"`
 }
 
 // Pipeline represents a zenhub pipeline.
@@ -20,6 +25,77 @@ type Issue struct {
 	Estimate    Estimate `json:"estimate"`
 	Position    int      `json:"position"`
 	IsEpic      bool     `json:"is_epic"`
+	// RepoID is the ID of the repository that the issue belongs to. It is only set by GetWorkspaceBoard.
+	RepoID int `json:"repo_id"`
+}
+
+// Workspace represents a zenhub workspace, which combines the boards of several repositories.
+type Workspace struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	Repositories []int  `json:"repositories"`
+}
+
+// IssueData represents the ZenHub data for a single issue.
+type IssueData struct {
+	Estimate Estimate      `json:"estimate"`
+	Pipeline IssuePipeline `json:"pipeline"`
+	IsEpic   bool          `json:"is_epic"`
+}
+
+// IssuePipeline represents the pipeline that an issue is in.
+type IssuePipeline struct {
+	PipelineID string `json:"pipeline_id"`
+	Name       string `json:"name"`
+}
+
+// IssueEvent represents a change that was made to an issue in ZenHub, such as it being moved between pipelines
+// (a "transferIssue" event) or estimated.
+type IssueEvent struct {
+	// UserID is the github ID of the user who made the change.
+	UserID       int            `json:"user_id"`
+	Type         string         `json:"type"`
+	CreatedAt    time.Time      `json:"created_at"`
+	FromPipeline *EventPipeline `json:"from_pipeline"`
+	ToPipeline   *EventPipeline `json:"to_pipeline"`
+}
+
+// EventPipeline identifies a pipeline in an issue event.
+type EventPipeline struct {
+	Name string `json:"name"`
+}
+
+// Release represents a zenhub release report, which tracks the progress of issues (possibly from several
+// repositories) towards a desired end date.
+type Release struct {
+	ID             string     `json:"release_id"`
+	Title          string     `json:"title"`
+	Description    string     `json:"description"`
+	StartDate      *time.Time `json:"start_date"`
+	DesiredEndDate *time.Time `json:"desired_end_date"`
+	CreatedAt      time.Time  `json:"created_at"`
+	ClosedAt       *time.Time `json:"closed_at"`
+	State          string     `json:"state"`
+	Repositories   []int      `json:"repositories"`
+}
+
+// ReleaseIssue identifies an issue that belongs to a release.
+type ReleaseIssue struct {
+	RepoID      int `json:"repo_id"`
+	IssueNumber int `json:"issue_number"`
+}
+
+// MilestoneStartDate represents the start date that ZenHub records for a github milestone.
+type MilestoneStartDate struct {
+	StartDate *time.Time `json:"start_date"`
+}
+
+// ConnectedPullRequest identifies a pull request that is connected to an issue in ZenHub.
+type ConnectedPullRequest struct {
+	Number int
+	// Repo is the full name of the pull request's repository (i.e. eltorocorp/zencli).
+	Repo string
 }
 
 // Estimate represents a zenhub estimate.
@@ -32,3 +108,20 @@ type PipelineMove struct {
 	PipelineID string `json:"pipeline_id"`
 	Position   string `json:"position"`
 }
+
+// IssueEstimate represents the estimate to apply to an issue.
+type IssueEstimate struct {
+	Estimate int `json:"estimate"`
+}
+
+// EpicUpdate represents a set of issues to add to or remove from an epic.
+type EpicUpdate struct {
+	AddIssues    []EpicIssue `json:"add_issues,omitempty"`
+	RemoveIssues []EpicIssue `json:"remove_issues,omitempty"`
+}
+
+// EpicIssue identifies an issue that belongs to an epic.
+type EpicIssue struct {
+	RepoID      int `json:"repo_id"`
+	IssueNumber int `json:"issue_number"`
+}